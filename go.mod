@@ -6,7 +6,6 @@ require (
 	github.com/dpup/gohubbub v0.0.0-20140517235056-2dc6969d22d8
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/go-telegram-bot-api/telegram-bot-api v1.0.1-0.20201107014523-54104a08f947
-	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	golang.org/x/net v0.0.0-20210119194325-5f4716e94777
 	golang.org/x/oauth2 v0.0.0-20210210192628-66670185b0cd
 	google.golang.org/api v0.40.0
@@ -4,35 +4,46 @@ import (
 	"encoding/json"
 	"flag"
 	"io/ioutil"
+	"log"
 	"os"
 	"os/signal"
 	"time"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/server"
-	"github.com/golang/glog"
 )
 
 var settingPath = flag.String("setting", "setting.json", "The path of setting file")
+var simulate = flag.Bool("simulate", false, "Simulate the notification pipeline with fakes on a scratch database, then exit")
 
 func main() {
-	flag.Parse()                   // Parse cmd arguments.
-	glog.CopyStandardLogTo("INFO") // Redirect std log to glog
+	flag.Parse() // Parse cmd arguments.
+
+	// Redirect std log to structured logs
+	log.SetFlags(0)
+	log.SetOutput(logging.With().Writer(logging.LevelInfo))
 
 	// Load setting file
 	b, err := ioutil.ReadFile(*settingPath)
 	if err != nil {
-		glog.Fatalln(err)
+		logging.With("error", err).Fatal("Failed to read setting")
 	}
 
 	var setting server.Setting
 	if err := json.Unmarshal(b, &setting); err != nil {
-		glog.Fatalln(err)
+		logging.With("error", err).Fatal("Failed to parse setting")
+	}
+
+	// Run simulation instead of server
+	if *simulate {
+		runSimulation(setting)
+		return
 	}
 
 	// Initialize server
 	server, err := server.NewServer(setting)
 	if err != nil {
-		glog.Fatalln(err)
+		logging.With("error", err).Fatal("Failed to initialize server")
 	}
 
 	// Handle SIGINT to cleanup program
@@ -40,15 +51,29 @@ func main() {
 	signal.Notify(signalCh, os.Interrupt)
 	go func() {
 		<-signalCh
-		glog.Info("Graceful shutdown server...")
+		logging.With().Info("Graceful shutdown server...")
 		server.Close()
 
 		time.Sleep(time.Second * 5)
 
-		glog.Info("Goodbye")
+		logging.With().Info("Goodbye")
 		os.Exit(0)
 	}()
 
 	// Start server
 	server.ListenAndServe()
 }
+
+func runSimulation(setting server.Setting) {
+	sim, err := server.NewSimulation(setting.DBPath)
+	if err != nil {
+		logging.With("error", err).Fatal("Failed to initialize simulation")
+	}
+	defer sim.Close()
+
+	if err := sim.Run(); err != nil {
+		logging.With("error", err).Fatal("Simulation failed")
+	}
+
+	logging.With().Info("Simulation passed")
+}
@@ -0,0 +1,175 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+)
+
+// Point is a sample on a line chart.
+type Point struct {
+	X, Y float64
+}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gridColor  = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	axisColor  = color.RGBA{0x60, 0x60, 0x60, 0xff}
+	lineColor  = color.RGBA{0xff, 0x00, 0x00, 0xff}
+	fillColor  = color.RGBA{0xff, 0xd6, 0xd6, 0xff}
+)
+
+const (
+	margin   = 48
+	gridRows = 4
+)
+
+// LineChart renders points as a PNG encoded line chart.
+// Points must be sorted by X.
+func LineChart(points []Point, width, height int) ([]byte, error) {
+	if len(points) < 2 {
+		return nil, errors.New("chart: at least 2 points are required")
+	} else if width <= 2*margin || height <= 2*margin {
+		return nil, errors.New("chart: image size too small")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), background)
+
+	// Plot area.
+	area := image.Rect(margin, margin/2, width-margin/2, height-margin)
+
+	minX, maxX := points[0].X, points[len(points)-1].X
+	var maxY float64
+	for _, p := range points {
+		if p.Y > maxY {
+			maxY = p.Y
+		}
+	}
+
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	if maxY == 0 {
+		maxY = 1
+	}
+	maxY *= 1.1
+
+	toPixel := func(p Point) (int, int) {
+		x := area.Min.X + int((p.X-minX)/(maxX-minX)*float64(area.Dx()-1))
+		y := area.Max.Y - 1 - int(p.Y/maxY*float64(area.Dy()-1))
+		return x, y
+	}
+
+	// Draw horizontal grid & y labels.
+	for i := 0; i <= gridRows; i++ {
+		y := area.Max.Y - 1 - i*(area.Dy()-1)/gridRows
+		for x := area.Min.X; x < area.Max.X; x++ {
+			img.Set(x, y, gridColor)
+		}
+
+		label := compactNumber(maxY * float64(i) / gridRows)
+		drawText(img, area.Min.X-4-textWidth(label), y-glyphHeight/2, label, axisColor)
+	}
+
+	// Fill area under the line.
+	for i := 1; i < len(points); i++ {
+		x0, y0 := toPixel(points[i-1])
+		x1, y1 := toPixel(points[i])
+
+		for x := x0; x <= x1; x++ {
+			y := y0
+			if x1 != x0 {
+				y = y0 + (y1-y0)*(x-x0)/(x1-x0)
+			}
+			for yy := y; yy < area.Max.Y; yy++ {
+				img.Set(x, yy, fillColor)
+			}
+		}
+	}
+
+	// Draw line.
+	for i := 1; i < len(points); i++ {
+		x0, y0 := toPixel(points[i-1])
+		x1, y1 := toPixel(points[i])
+		drawLine(img, x0, y0, x1, y1, lineColor)
+	}
+
+	// Draw axes.
+	for x := area.Min.X; x < area.Max.X; x++ {
+		img.Set(x, area.Max.Y, axisColor)
+	}
+	for y := area.Min.Y; y <= area.Max.Y; y++ {
+		img.Set(area.Min.X-1, y, axisColor)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a 2 pixels thick line with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0, y0-1, c)
+
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// compactNumber formats n like 1.2k or 3.4M.
+func compactNumber(n float64) string {
+	switch {
+	case n >= 1e6:
+		return strconv.FormatFloat(n/1e6, 'f', 1, 64) + "M"
+	case n >= 1e3:
+		return strconv.FormatFloat(n/1e3, 'f', 1, 64) + "k"
+	default:
+		return strconv.Itoa(int(n))
+	}
+}
@@ -0,0 +1,63 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+// A tiny 3x5 bitmap font which only covers characters used by labels.
+// Each row is 3 bits wide, most significant bit on the left.
+var glyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'.': {0, 0, 0, 0, 2},
+	':': {0, 2, 0, 2, 0},
+	'k': {4, 5, 6, 5, 5},
+	'M': {5, 7, 7, 5, 5},
+}
+
+const (
+	glyphScale  = 2
+	glyphWidth  = 3 * glyphScale
+	glyphHeight = 5 * glyphScale
+	glyphGap    = glyphScale
+)
+
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*(glyphWidth+glyphGap) - glyphGap
+}
+
+// drawText draws text with its top left corner at (x, y).
+func drawText(img *image.RGBA, x, y int, text string, c color.Color) {
+	for _, r := range text {
+		if g, ok := glyphs[r]; ok {
+			for row, bits := range g {
+				for col := 0; col < 3; col++ {
+					if bits&(4>>uint(col)) == 0 {
+						continue
+					}
+
+					for dy := 0; dy < glyphScale; dy++ {
+						for dx := 0; dx < glyphScale; dx++ {
+							img.Set(x+col*glyphScale+dx, y+row*glyphScale+dy, c)
+						}
+					}
+				}
+			}
+		}
+
+		x += glyphWidth + glyphGap
+	}
+}
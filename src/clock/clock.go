@@ -0,0 +1,216 @@
+// Package clock abstracts time, so timing logic can be driven deterministically by tests.
+package clock
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Clock tells & waits for time.
+//
+// Goroutines which wait on the clock should be started by Go,
+// so Fake knows when they are all blocked.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	AfterFunc(d time.Duration, f func())
+	Go(f func())
+}
+
+// Real is the Clock of wall time.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                      { return time.Now() }
+func (realClock) Sleep(d time.Duration)               { time.Sleep(d) }
+func (realClock) AfterFunc(d time.Duration, f func()) { time.AfterFunc(d, f) }
+func (realClock) Go(f func())                         { go f() }
+
+// Fake is a Clock which only moves when advanced.
+//
+// It counts goroutines started by Go & AfterFunc as busy until they return
+// or sleep on the clock, so Advance & Wait know when everything is blocked
+// without sleeping in wall time.
+type Fake struct {
+	mutex   sync.Mutex
+	idle    *sync.Cond
+	now     time.Time
+	timers  []fakeTimer
+	counter int
+	busy    int
+	// tracked are IDs of goroutines started by Go & AfterFunc.
+	tracked map[uint64]bool
+}
+
+type fakeTimer struct {
+	at  time.Time
+	seq int
+	f   func()
+	// wake is true if f wakes a tracked sleeping goroutine, instead of starting a new one.
+	wake bool
+}
+
+// NewFake returns a pointer to a new `Fake` starts at now.
+func NewFake(now time.Time) *Fake {
+	c := &Fake{now: now, tracked: make(map[uint64]bool)}
+	c.idle = sync.NewCond(&c.mutex)
+	return c
+}
+
+// Now returns the fake current time.
+func (c *Fake) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// Sleep blocks until the clock is advanced by d.
+// Goroutines not started by Go or AfterFunc may sleep too,
+// but they are never waited for.
+func (c *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	ch := make(chan struct{})
+	id := goid()
+
+	c.mutex.Lock()
+	tracked := c.tracked[id]
+	c.addTimer(d, func() { close(ch) }, tracked)
+	if tracked {
+		c.done()
+	}
+	c.mutex.Unlock()
+
+	<-ch
+}
+
+// AfterFunc calls f in its own goroutine once the clock is advanced by d.
+func (c *Fake) AfterFunc(d time.Duration, f func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.addTimer(d, f, false)
+}
+
+// Go runs f in a new goroutine, which is busy until it returns or sleeps.
+func (c *Fake) Go(f func()) {
+	c.mutex.Lock()
+	c.busy++
+	c.mutex.Unlock()
+
+	go c.run(f)
+}
+
+func (c *Fake) run(f func()) {
+	id := goid()
+
+	c.mutex.Lock()
+	c.tracked[id] = true
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.tracked, id)
+		c.done()
+		c.mutex.Unlock()
+	}()
+
+	f()
+}
+
+// goid returns ID of the calling goroutine, parsed from its stack header
+// "goroutine <id> [...]".
+func goid() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// done marks a goroutine as not busy, must be called with mutex held.
+func (c *Fake) done() {
+	c.busy--
+	if c.busy <= 0 {
+		c.idle.Broadcast()
+	}
+}
+
+// addTimer must be called with mutex held.
+func (c *Fake) addTimer(d time.Duration, f func(), wake bool) {
+	c.counter++
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), seq: c.counter, f: f, wake: wake})
+
+	// Keep timers ordered by time, then by creation.
+	sort.Slice(c.timers, func(i, j int) bool {
+		if c.timers[i].at.Equal(c.timers[j].at) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+}
+
+// Wait blocks until every goroutine started by Go & AfterFunc has returned or sleeps.
+func (c *Fake) Wait() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.wait()
+}
+
+// wait must be called with mutex held.
+func (c *Fake) wait() {
+	for c.busy > 0 {
+		c.idle.Wait()
+	}
+}
+
+// Advance moves the clock forward by d. Due timers fire one by one in order,
+// each after everything woken by the previous one is blocked again.
+func (c *Fake) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	target := c.now.Add(d)
+
+	for {
+		c.wait()
+
+		if len(c.timers) == 0 || c.timers[0].at.After(target) {
+			c.now = target
+			return
+		}
+
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+
+		c.busy++
+		if t.wake {
+			t.f()
+		} else {
+			go c.run(t.f)
+		}
+	}
+}
+
+// Pending returns the number of timers waiting to fire.
+func (c *Fake) Pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.timers)
+}
@@ -0,0 +1,174 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeAdvanceRunsSleepers(t *testing.T) {
+	c := NewFake(epoch)
+
+	var mutex sync.Mutex
+	var ticks []time.Time
+
+	c.Go(func() {
+		for i := 0; i < 3; i++ {
+			c.Sleep(time.Minute)
+
+			mutex.Lock()
+			ticks = append(ticks, c.Now())
+			mutex.Unlock()
+		}
+	})
+
+	c.Advance(150 * time.Second)
+
+	mutex.Lock()
+	got := len(ticks)
+	mutex.Unlock()
+
+	if got != 2 {
+		t.Fatalf("ticks after 2m30s = %d, want 2", got)
+	}
+	if !ticks[1].Equal(epoch.Add(2 * time.Minute)) {
+		t.Errorf("second tick = %v, want %v", ticks[1], epoch.Add(2*time.Minute))
+	}
+	if !c.Now().Equal(epoch.Add(150 * time.Second)) {
+		t.Errorf("now = %v, want %v", c.Now(), epoch.Add(150*time.Second))
+	}
+
+	c.Advance(time.Minute)
+	c.Wait()
+
+	if len(ticks) != 3 {
+		t.Errorf("ticks after 3m30s = %d, want 3", len(ticks))
+	}
+	if c.Pending() != 0 {
+		t.Errorf("pending timers = %d, want 0", c.Pending())
+	}
+}
+
+func TestFakeAfterFuncOrder(t *testing.T) {
+	c := NewFake(epoch)
+
+	var mutex sync.Mutex
+	var order []string
+
+	record := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			order = append(order, name)
+			mutex.Unlock()
+		}
+	}
+
+	c.AfterFunc(2*time.Second, record("b"))
+	c.AfterFunc(time.Second, record("a"))
+	c.AfterFunc(2*time.Second, record("c"))
+	c.AfterFunc(time.Hour, record("late"))
+
+	c.Advance(2 * time.Second)
+	c.Wait()
+
+	want := []string{"a", "b", "c"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+
+	if c.Pending() != 1 {
+		t.Errorf("pending timers = %d, want 1", c.Pending())
+	}
+}
+
+func TestFakeTimersScheduledByTimers(t *testing.T) {
+	c := NewFake(epoch)
+
+	fired := make(chan time.Time, 1)
+
+	// A timer scheduled by a fired callback is due within the same advance.
+	c.AfterFunc(time.Second, func() {
+		c.AfterFunc(time.Second, func() {
+			fired <- c.Now()
+		})
+	})
+
+	c.Advance(5 * time.Second)
+	c.Wait()
+
+	select {
+	case at := <-fired:
+		if !at.Equal(epoch.Add(2 * time.Second)) {
+			t.Errorf("fired at %v, want %v", at, epoch.Add(2*time.Second))
+		}
+	default:
+		t.Fatal("nested timer not fired")
+	}
+}
+
+func TestFakeSleepNonPositive(t *testing.T) {
+	c := NewFake(epoch)
+
+	done := make(chan struct{})
+	c.Go(func() {
+		c.Sleep(0)
+		c.Sleep(-time.Second)
+		close(done)
+	})
+
+	c.Wait()
+
+	select {
+	case <-done:
+	default:
+		t.Fatal("non-positive sleep blocked")
+	}
+}
+
+func TestFakeSleepUntracked(t *testing.T) {
+	c := NewFake(epoch)
+
+	woken := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(woken)
+	}()
+
+	// Wait for the untracked sleeper, it doesn't affect busy goroutines.
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	c.Go(func() {
+		<-release
+		close(done)
+	})
+
+	waited := make(chan struct{})
+	go func() {
+		c.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait() returned while a tracked goroutine is busy")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+	<-waited
+
+	c.Advance(time.Minute)
+	<-woken
+}
@@ -0,0 +1,184 @@
+// Package filter implements the notification filter expression language.
+//
+// An expression is made of terms combined by AND (&), OR (|) and NOT (!, -)
+// with parentheses, terms next to each other are implicitly AND-ed.
+// NOT binds tighter than AND, AND binds tighter than OR.
+//
+// Terms:
+//
+//	word          title contains word, case-insensitively
+//	"a phrase"    title contains phrase
+//	=word         title contains word at word boundaries
+//	/regexp/      title matches regular expression, case-insensitively
+//	=/regexp/     title matches regular expression at word boundaries
+//	desc:...      match description instead of title
+//	tag:...       match any tag, =tag & =/regexp/ match a whole tag
+//	duration>1h   compare duration by >, >=, <, <=, =,
+//	              never holds before the live ends
+//	members       video is members-only, also members:yes & members:no
+package filter
+
+import (
+	"errors"
+	"time"
+)
+
+// Maximum length of expression in characters.
+const maxLength = 1024
+
+// Video is the information of a video used in matching.
+type Video struct {
+	Title       string
+	Description string
+	Tags        []string
+	Duration    time.Duration
+	MembersOnly bool
+
+	// Unfinished is true for upcoming & live lives, which have no duration yet.
+	Unfinished bool
+}
+
+// Expr is a parsed filter expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses & validates a filter expression.
+func Parse(src string) (*Expr, error) {
+	if len([]rune(src)) > maxLength {
+		return nil, errors.New("expression too long")
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().typ == tokenEOF {
+		return nil, errors.New("empty expression")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, errorf(t.pos, "unexpected %q", t.val)
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// Match reports whether video matches the expression.
+func (e *Expr) Match(v Video) bool {
+	return e.root.match(v)
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.typ != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// parseOr parses: and (OR and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().typ == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses: not ([AND] not)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().typ {
+		case tokenAnd:
+			p.next()
+		case tokenNot, tokenLParen, tokenTerm:
+			// Implicit AND.
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+// parseNot parses: NOT not | primary
+func (p *parser) parseNot() (node, error) {
+	if p.peek().typ == tokenNot {
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: ( or ) | term
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.typ {
+	case tokenLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.typ != tokenRParen {
+			if c.typ == tokenEOF {
+				return nil, errorf(t.pos, "unclosed parenthesis")
+			}
+			return nil, errorf(c.pos, "expected ')' but got %q", c.val)
+		}
+		return n, nil
+	case tokenTerm:
+		return parseTerm(t)
+	case tokenEOF:
+		return nil, errorf(t.pos, "unexpected end of expression")
+	default:
+		return nil, errorf(t.pos, "unexpected %q", t.val)
+	}
+}
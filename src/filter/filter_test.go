@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	archive := Video{
+		Title:       "【Minecraft】Building a castle! #3",
+		Description: "Thanks for watching, members get early access.",
+		Tags:        []string{"Minecraft", "Hololive"},
+		Duration:    90 * time.Minute,
+	}
+	upcoming := Video{
+		Title:      "Karaoke Night",
+		Tags:       []string{"karaoke"},
+		Unfinished: true,
+	}
+	members := Video{
+		Title:       "Members only chat",
+		MembersOnly: true,
+		Duration:    30 * time.Minute,
+	}
+
+	tests := []struct {
+		expr  string
+		video Video
+		want  bool
+	}{
+		// Words & phrases.
+		{"minecraft", archive, true},
+		{"MINECRAFT", archive, true},
+		{"craft", archive, true},
+		{"=craft", archive, false},
+		{"=minecraft", archive, true},
+		{`"a castle"`, archive, true},
+		{`"castle a"`, archive, false},
+
+		// Regular expressions.
+		{"/build(ing)?/", archive, true},
+		{"/^karaoke/", upcoming, true},
+		{"/#\\d+/", archive, true},
+		{"=/build/", archive, false},
+		{"=/build(ing)?/", archive, true},
+		{"=/cast/", archive, false},
+		{"tag:=/holo.*/", archive, true},
+		{"tag:=/holo/", archive, false},
+		{"tag:/holo/", archive, true},
+
+		// Fields.
+		{"desc:early", archive, true},
+		{"desc:castle", archive, false},
+		{"tag:=minecraft", archive, true},
+		{"tag:=mine", archive, false},
+		{"tag:mine", archive, true},
+
+		// Duration.
+		{"duration>1h", archive, true},
+		{"duration<=1h", archive, false},
+		{"duration:90", archive, true},
+		{"duration>=1h30m", archive, true},
+		{"duration<1h", upcoming, false},
+		{"duration>1h", upcoming, false},
+		{"duration=0s", upcoming, false},
+		{"duration<1h", members, true},
+
+		// Members-only.
+		{"members", members, true},
+		{"members:no", members, false},
+		{"members:no", archive, true},
+
+		// Operators.
+		{"minecraft castle", archive, true},
+		{"minecraft & karaoke", archive, false},
+		{"minecraft | karaoke", upcoming, true},
+		{"minecraft OR karaoke", upcoming, true},
+		{"-karaoke", upcoming, false},
+		{"!members", archive, true},
+		{"NOT minecraft", archive, false},
+		{"(karaoke | minecraft) -members", members, false},
+		{"karaoke | minecraft castle", archive, true},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+
+		if got := expr.Match(tt.video); got != tt.want {
+			t.Errorf("Parse(%q).Match(%q) = %v, want %v", tt.expr, tt.video.Title, got, tt.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"(minecraft", 1},
+		{"minecraft)", 10},
+		{"minecraft &", 12},
+		{`"unterminated`, 1},
+		{"/unterminated", 1},
+		{"/(/", 1},
+		{"duration>soon", 1},
+		{"members:maybe", 1},
+		{`a"b"`, 1},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+
+		e, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want SyntaxError", tt.expr, err)
+		} else if e.Pos != tt.pos {
+			t.Errorf("Parse(%q) error at column %d, want %d", tt.expr, e.Pos, tt.pos)
+		}
+	}
+
+	if _, err := Parse(""); err == nil {
+		t.Error(`Parse("") error = nil, want error`)
+	}
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"fmt"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm
+)
+
+type token struct {
+	typ tokenType
+	pos int
+	val string
+}
+
+// SyntaxError is an error of invalid filter expression.
+type SyntaxError struct {
+	// Pos is the 1-based character position where error occurs.
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// lex splits expression into tokens, positions are counted in runes.
+func lex(src string) ([]token, error) {
+	rs := []rune(src)
+	var tokens []token
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, i, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, i, ")"})
+			i++
+		case r == '&' || r == '|':
+			typ := tokenAnd
+			if r == '|' {
+				typ = tokenOr
+			}
+			tokens = append(tokens, token{typ, i, string(r)})
+			// Accept doubled operators, e.g. && & ||.
+			if i+1 < len(rs) && rs[i+1] == r {
+				i++
+			}
+			i++
+		case r == '!' || (r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1])):
+			tokens = append(tokens, token{tokenNot, i, string(r)})
+			i++
+		default:
+			start := i
+			term, next, err := lexTerm(rs, i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+
+			switch term {
+			case "AND":
+				tokens = append(tokens, token{tokenAnd, start, term})
+			case "OR":
+				tokens = append(tokens, token{tokenOr, start, term})
+			case "NOT":
+				tokens = append(tokens, token{tokenNot, start, term})
+			default:
+				tokens = append(tokens, token{tokenTerm, start, term})
+			}
+		}
+	}
+
+	return append(tokens, token{tokenEOF, len(rs), ""}), nil
+}
+
+// lexTerm reads a term starts from i, quoted phrases & regular expressions
+// may contain spaces & parentheses.
+func lexTerm(rs []rune, i int) (string, int, error) {
+	start := i
+
+	for i < len(rs) {
+		r := rs[i]
+
+		if unicode.IsSpace(r) || r == '(' || r == ')' {
+			break
+		}
+
+		prefix := string(rs[start:i])
+
+		switch {
+		case r == '"':
+			end, err := skipDelimited(rs, i, '"', "unterminated quoted phrase")
+			if err != nil {
+				return "", 0, err
+			}
+			i = end
+		case r == '/' && (prefix == "" || prefix == "=" || prefix[len(prefix)-1] == ':'):
+			end, err := skipDelimited(rs, i, '/', "unterminated regular expression")
+			if err != nil {
+				return "", 0, err
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+
+	return string(rs[start:i]), i, nil
+}
+
+// skipDelimited returns the position after closing delimiter,
+// delimiter can be escaped by backslash.
+func skipDelimited(rs []rune, i int, delim rune, msg string) (int, error) {
+	for j := i + 1; j < len(rs); j++ {
+		if rs[j] == '\\' {
+			j++
+		} else if rs[j] == delim {
+			return j + 1, nil
+		}
+	}
+
+	return 0, errorf(i, msg)
+}
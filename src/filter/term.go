@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type node interface {
+	match(v Video) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(v Video) bool { return n.left.match(v) && n.right.match(v) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(v Video) bool { return n.left.match(v) || n.right.match(v) }
+
+type notNode struct{ n node }
+
+func (n notNode) match(v Video) bool { return !n.n.match(v) }
+
+type field int
+
+const (
+	titleField field = iota
+	descField
+	tagField
+)
+
+var fieldNames = map[string]field{
+	"title":       titleField,
+	"desc":        descField,
+	"description": descField,
+	"tag":         tagField,
+	"tags":        tagField,
+}
+
+// textNode matches text fields by substring, whole word or regexp.
+type textNode struct {
+	field field
+	value string
+	word  bool
+	re    *regexp.Regexp
+	// whole is re anchored at both ends, for whole tags.
+	whole *regexp.Regexp
+}
+
+func (n textNode) match(v Video) bool {
+	switch n.field {
+	case descField:
+		return n.matchText(v.Description)
+	case tagField:
+		for _, tag := range v.Tags {
+			if n.word {
+				// Whole tag.
+				if n.whole != nil && n.whole.MatchString(tag) || n.whole == nil && strings.EqualFold(tag, n.value) {
+					return true
+				}
+			} else if n.matchText(tag) {
+				return true
+			}
+		}
+		return false
+	default:
+		return n.matchText(v.Title)
+	}
+}
+
+func (n textNode) matchText(text string) bool {
+	if n.re != nil && !n.word {
+		return n.re.MatchString(text)
+	} else if n.re != nil {
+		for _, loc := range n.re.FindAllStringIndex(text, -1) {
+			if isBoundary(text, loc[0], true) && isBoundary(text, loc[1], false) {
+				return true
+			}
+		}
+		return false
+	}
+
+	text = strings.ToLower(text)
+	if !n.word {
+		return strings.Contains(text, n.value)
+	}
+
+	for i := 0; ; {
+		j := strings.Index(text[i:], n.value)
+		if j < 0 {
+			return false
+		}
+		j += i
+
+		end := j + len(n.value)
+		if isBoundary(text, j, true) && isBoundary(text, end, false) {
+			return true
+		}
+
+		i = j + 1
+	}
+}
+
+// isBoundary reports whether position i of text is a word boundary,
+// looking backward before a match start, or forward after a match end.
+func isBoundary(text string, i int, backward bool) bool {
+	var r rune
+	if backward {
+		r, _ = utf8.DecodeLastRuneInString(text[:i])
+	} else {
+		r, _ = utf8.DecodeRuneInString(text[i:])
+	}
+
+	// RuneError means it's the start or end of text.
+	return r == utf8.RuneError || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// durationNode compares video duration.
+type durationNode struct {
+	op  string
+	dur time.Duration
+}
+
+func (n durationNode) match(v Video) bool {
+	// Duration of unfinished lives is zero, it's not comparable.
+	if v.Unfinished {
+		return false
+	}
+
+	switch n.op {
+	case ">":
+		return v.Duration > n.dur
+	case ">=":
+		return v.Duration >= n.dur
+	case "<":
+		return v.Duration < n.dur
+	case "<=":
+		return v.Duration <= n.dur
+	default:
+		return v.Duration == n.dur
+	}
+}
+
+type membersNode struct{ want bool }
+
+func (n membersNode) match(v Video) bool { return v.MembersOnly == n.want }
+
+func parseTerm(t token) (node, error) {
+	term := t.val
+
+	// Duration comparison, e.g. duration>=1h30m.
+	if strings.HasPrefix(strings.ToLower(term), "duration") && len(term) > len("duration") {
+		rest := term[len("duration"):]
+		if strings.ContainsAny(rest[:1], "<>=:") {
+			return parseDuration(t, rest)
+		}
+	}
+
+	// Members-only flag.
+	switch strings.ToLower(term) {
+	case "members", "members:yes", "members:true":
+		return membersNode{true}, nil
+	case "members:no", "members:false":
+		return membersNode{false}, nil
+	}
+
+	n := textNode{field: titleField}
+
+	if i := strings.Index(term, ":"); i > 0 {
+		if f, ok := fieldNames[strings.ToLower(term[:i])]; ok {
+			n.field = f
+			term = term[i+1:]
+		} else if strings.ToLower(term[:i]) == "members" {
+			return nil, errorf(t.pos, "members should be yes or no")
+		}
+	}
+
+	if strings.HasPrefix(term, "=") {
+		n.word = true
+		term = term[1:]
+	}
+
+	switch {
+	case len(term) >= 2 && strings.HasPrefix(term, "/") && strings.HasSuffix(term, "/"):
+		pattern := strings.ReplaceAll(term[1:len(term)-1], `\/`, "/")
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, errorf(t.pos, "invalid regular expression: %v", err)
+		}
+		n.re = re
+		if n.word {
+			n.whole = regexp.MustCompile("(?i)^(?:" + pattern + ")$")
+		}
+		return n, nil
+	case len(term) >= 2 && strings.HasPrefix(term, `"`) && strings.HasSuffix(term, `"`):
+		term = strings.ReplaceAll(term[1:len(term)-1], `\"`, `"`)
+	case strings.Contains(term, `"`):
+		return nil, errorf(t.pos, "quoted phrase should be a whole term")
+	}
+
+	if term == "" {
+		return nil, errorf(t.pos, "empty term")
+	}
+
+	n.value = strings.ToLower(term)
+	return n, nil
+}
+
+func parseDuration(t token, rest string) (node, error) {
+	op := rest[:1]
+	if len(rest) > 1 && rest[1] == '=' && op != "=" && op != ":" {
+		op = rest[:2]
+	}
+	if op == ":" {
+		op = "="
+	}
+
+	value := rest[len(op):]
+	if op == "=" && strings.HasPrefix(rest, ":") {
+		value = rest[1:]
+	}
+
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		// Plain number is counted in minutes.
+		minutes, nerr := strconv.Atoi(value)
+		if nerr != nil {
+			return nil, errorf(t.pos, "invalid duration %q, e.g. duration>1h30m", value)
+		}
+		dur = time.Duration(minutes) * time.Minute
+	}
+
+	return durationNode{op: op, dur: dur}, nil
+}
@@ -0,0 +1,96 @@
+package hub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+)
+
+// Subscriber is the WebSub calls used by server.
+// It's implemented by Client, and Fake for offline testing.
+type Subscriber interface {
+	Subscribe(channelID string)
+	Unsubscribe(channelID string)
+	Start()
+	Leases() map[string]Lease
+}
+
+var _ Subscriber = (*Client)(nil)
+
+// fakeLease is the lease of Fake, same as the default of Google hub.
+const fakeLease = 5 * 24 * time.Hour
+
+// Fake is an in-memory Subscriber, which publishes feeds on demand
+// instead of receiving them from the hub.
+type Fake struct {
+	mutex      sync.Mutex
+	subscribed map[string]Lease
+	feedsCh    chan<- Feed
+}
+
+var _ Subscriber = (*Fake)(nil)
+
+// NewFake returns a pointer to a new `Fake` object & its feeds channel.
+func NewFake() (*Fake, FeedsChannel) {
+	feedsCh := make(chan Feed, 64)
+
+	return &Fake{
+		subscribed: make(map[string]Lease),
+		feedsCh:    feedsCh,
+	}, feedsCh
+}
+
+// Subscribe records channel as subscribed & verified immediately.
+func (f *Fake) Subscribe(channelID string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	f.subscribed[channelID] = Lease{Verified: now, Expires: now.Add(fakeLease)}
+}
+
+// Unsubscribe records channel as unsubscribed.
+func (f *Fake) Unsubscribe(channelID string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.subscribed, channelID)
+}
+
+// Start does nothing, there's no lease to keep.
+func (f *Fake) Start() {}
+
+// IsSubscribed reports whether channel is subscribed.
+func (f *Fake) IsSubscribed(channelID string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	_, ok := f.subscribed[channelID]
+	return ok
+}
+
+// Leases returns leases of subscribed channels.
+func (f *Fake) Leases() map[string]Lease {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	leases := make(map[string]Lease, len(f.subscribed))
+	for id, l := range f.subscribed {
+		leases[id] = l
+	}
+
+	return leases
+}
+
+// Publish delivers feed as if it's notified by the hub.
+func (f *Fake) Publish(feed Feed) {
+	if feed.ID == "" {
+		feed.ID = logging.NewID()
+	}
+	if feed.Received.IsZero() {
+		feed.Received = time.Now()
+	}
+
+	f.feedsCh <- feed
+}
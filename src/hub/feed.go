@@ -3,12 +3,18 @@ package hub
 import (
 	"bytes"
 	"encoding/xml"
+	"time"
 )
 
 // Feed ...
 type Feed struct {
 	Entry        *Entry        `xml:"entry"`
 	DeletedEntry *DeletedEntry `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+
+	// ID is the correlation ID of feed, for tracing it in logs.
+	ID string `xml:"-"`
+	// Received is the time feed is received from the hub.
+	Received time.Time `xml:"-"`
 }
 
 // Entry ...
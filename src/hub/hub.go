@@ -2,20 +2,44 @@ package hub
 
 import (
 	"encoding/xml"
-	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
 	"github.com/dpup/gohubbub"
 )
 
 const googleHub = "http://pubsubhubbub.appspot.com"
 const topicURLPrefix = "https://www.youtube.com/xml/feeds/videos.xml?channel_id="
 
+var (
+	feedsReceived = metrics.NewCounter("notifier_hub_feeds_received_total", "Feeds received from the hub.")
+	feedsParsed   = metrics.NewCounter("notifier_hub_feeds_parsed_total", "Feeds parsed successfully.")
+	feedsFailed   = metrics.NewCounter("notifier_hub_feeds_failed_total", "Feeds failed to parse.")
+)
+
 // Client is a WebSub client that can receive notification from Youtube.
 type Client struct {
 	*gohubbub.Client
 
 	feedsCh chan<- Feed
+
+	// callback is the gohubbub callback handler.
+	callback http.Handler
+
+	leaseMutex sync.Mutex
+	leases     map[string]Lease
+}
+
+// Lease is a subscription verified by the hub.
+type Lease struct {
+	Verified time.Time
+	// Expires is zero if hub doesn't tell lease seconds.
+	Expires time.Time
 }
 
 type FeedsChannel <-chan Feed
@@ -25,13 +49,70 @@ func NewClient(addr string, mux *http.ServeMux) (*Client, FeedsChannel) {
 	client := gohubbub.NewClient(addr, "Hub Client")
 	feedsCh := make(chan Feed, 64)
 
-	client.RegisterHandler(mux)
+	callback := http.NewServeMux()
+	client.RegisterHandler(callback)
 
-	return &Client{
+	c := &Client{
 		Client: client,
 
 		feedsCh: feedsCh,
-	}, feedsCh
+
+		callback: callback,
+		leases:   make(map[string]Lease),
+	}
+
+	mux.HandleFunc("/push-callback/", c.callbackHandler)
+
+	return c, feedsCh
+}
+
+// callbackHandler passes hub callbacks to gohubbub, and records verified leases.
+func (client *Client) callbackHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if params.Get("hub.mode") != "subscribe" {
+		client.callback.ServeHTTP(w, r)
+		return
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	client.callback.ServeHTTP(sw, r)
+
+	if sw.status != http.StatusOK {
+		return
+	}
+
+	lease := Lease{Verified: time.Now()}
+	if seconds, err := strconv.Atoi(params.Get("hub.lease_seconds")); err == nil {
+		lease.Expires = lease.Verified.Add(time.Duration(seconds) * time.Second)
+	}
+
+	client.leaseMutex.Lock()
+	client.leases[strings.TrimPrefix(params.Get("hub.topic"), topicURLPrefix)] = lease
+	client.leaseMutex.Unlock()
+}
+
+// statusWriter records status code of response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Leases returns verified leases by channel ID.
+func (client *Client) Leases() map[string]Lease {
+	client.leaseMutex.Lock()
+	defer client.leaseMutex.Unlock()
+
+	leases := make(map[string]Lease, len(client.leases))
+	for id, l := range client.leases {
+		leases[id] = l
+	}
+
+	return leases
 }
 
 // Subscribe adds a handler will be called when an update notification is received.
@@ -48,15 +129,30 @@ func (client *Client) Subscribe(channelID string) {
 func (client *Client) Unsubscribe(channelID string) {
 	topicURL := topicURLPrefix + channelID
 	client.Client.Unsubscribe(topicURL)
+
+	client.leaseMutex.Lock()
+	delete(client.leases, channelID)
+	client.leaseMutex.Unlock()
 }
 
 func (client *Client) handler(contentType string, body []byte) {
 	var feed Feed
 
+	feedsReceived.Inc()
+
+	id := logging.NewID()
+	log := logging.With("cid", id)
+
 	err := xml.Unmarshal(body, &feed)
 	if err != nil {
-		fmt.Println(string(body))
+		feedsFailed.Inc()
+		log.Warning("Failed to parse feed", "error", err, "contentType", contentType, "body", string(body))
+	} else {
+		feedsParsed.Inc()
+		log.Info("Feed received", "contentType", contentType, "bytes", len(body))
 	}
 
+	feed.ID = id
+	feed.Received = time.Now()
 	client.feedsCh <- feed
 }
@@ -0,0 +1,196 @@
+package i18n
+
+var en = Catalog{
+	// Common
+	"common.empty_params": "Empty parameters.",
+	"common.na":           "N/A",
+	"common.internal":     "Internal server error",
+
+	// Relative time
+	"relative.now":    "now",
+	"relative.future": "in %s",
+	"relative.past":   "%s ago",
+
+	// Buttons
+	"button.record":                "Record",
+	"button.filter":                "Filter",
+	"button.remove":                "Remove",
+	"button.cancel":                "Cancel",
+	"button.blacklist":             "blacklist",
+	"button.whitelist":             "whitelist",
+	"button.global_filter":         "Chat-wide Filters",
+	"button.expression":            "expression",
+	"button.test_filter":           "Test filter",
+	"button.back_channel_list":     "« Back to Channel List",
+	"button.back_operation_list":   "« Back to Operation List",
+	"button.back_filter_operation": "« Back to Filter Operation",
+
+	// Subscription
+	"action.subscribe":          "Subscribe",
+	"action.remind":             "Remind",
+	"subscribe.usage":           "Please use %s to subscribe.",
+	"subscribe.success":         "%s %s successful.",
+	"subscribe.failed.internal": "%s %s failed.\nInternal server error.",
+	"subscribe.failed.invalid":  "%s %s failed.\nInvalid channel ID: %s.",
+	"subscribe.internal_error":  "Subscribe %s failed, internal server error",
+	"channel.invalid":           "%s is not a valid YouTube channel",
+	"channel.not_subscribed":    "You have not subscribed to %s",
+	"list.failed":               "Can not list subscribed channels.\nInternal server error.",
+	"list.header":               "You already subscribed following channels:",
+	"list.operation":            "Here it is: %s\nWhat do you want to do with the channel?",
+	"remove.confirm":            "Do you really want to remove %s?",
+	"remove.done":               "You have unsubscribed\n%s",
+	"remind.usage":              "Please use %s to set video reminder.",
+	"schedule.failed":           "Can not list live schedule.\nInternal server error.",
+	"schedule.empty":            "No upcoming live streams.",
+	"schedule.usage":            "Please use %s to list live schedule.",
+	"schedule.title":            "Live Schedule",
+	"schedule.all":              "Upcoming",
+	"schedule.today":            "Today",
+	"schedule.tomorrow":         "Tomorrow",
+	"schedule.week":             "This Week",
+	"schedule.now_streaming":    "Now Streaming",
+
+	// Weekdays
+	"weekday.0": "Sun",
+	"weekday.1": "Mon",
+	"weekday.2": "Tue",
+	"weekday.3": "Wed",
+	"weekday.4": "Thu",
+	"weekday.5": "Fri",
+	"weekday.6": "Sat",
+
+	// Timezone
+	"timezone.current":    "Current timezone: %s\nPlease use %s to change it, e.g. %s.",
+	"timezone.reset":      "Timezone reset to %s",
+	"timezone.reset_fail": "Failed to reset timezone, internal server error",
+	"timezone.invalid":    "%s is not a valid IANA timezone name",
+	"timezone.set":        "Timezone set to %s, current time is %s",
+	"timezone.set_fail":   "Failed to set timezone, internal server error",
+
+	// Language
+	"language.current":  "Current language: %s\nPlease use %s to change it.\nAvailable languages: %s",
+	"language.invalid":  "%s is not a supported language",
+	"language.set":      "Language set to %s",
+	"language.set_fail": "Failed to set language, internal server error",
+
+	// Template
+	"template.current":    "Current template:\n%s\n\nPlease use %s to set, %s to preview, %s to reset.\nAvailable fields: %s\nAvailable functions: %s",
+	"template.default":    "Default layout is used.",
+	"template.invalid":    "Invalid template: %s",
+	"template.set":        "Template updated, preview:",
+	"template.set_fail":   "Failed to set template, internal server error",
+	"template.reset":      "Template reset to default layout.",
+	"template.reset_fail": "Failed to reset template, internal server error",
+
+	// Opt-out
+	"optout.usage":    "Please use %s to skip restricted streams.",
+	"optout.set_fail": "Failed to set opt-out, internal server error",
+	"optout.notify":   "notify",
+	"optout.skip":     "skip",
+	"optout.skip_all": "skip (all channels)",
+	"optout.members":  "Members only",
+	"optout.age":      "Age restricted",
+	"optout.region":   "Region restricted",
+
+	// Search
+	"search.title":       "Channels matched %s:",
+	"search.empty":       "No channel matched %s.",
+	"search.failed":      "Failed to search channels, internal server error",
+	"search.subscribers": "%s subscribers",
+	"search.hidden":      "hidden subscribers",
+
+	// Guest
+	"guest.featuring": "🤝 Featuring %s",
+	"guest.current":   "Guest appearance notices: %s\nPlease use %s to change it.",
+	"guest.on":        "on",
+	"guest.off":       "off",
+	"guest.usage":     "Please use %s to set guest appearance notices.",
+	"guest.enabled":   "Streams featuring your subscribed channels as guests will be notified.",
+	"guest.disabled":  "Guest appearances will no longer be notified.",
+	"guest.set_fail":  "Failed to set guest appearance notices, internal server error",
+
+	// Photo
+	"photo.current":  "Thumbnail photo notices: %s\nPlease use %s to change it.",
+	"photo.on":       "on",
+	"photo.off":      "off",
+	"photo.usage":    "Please use %s to set thumbnail photo notices.",
+	"photo.enabled":  "New notices will be sent as thumbnail photos.",
+	"photo.disabled": "New notices will be sent as text messages.",
+	"photo.set_fail": "Failed to set thumbnail photo notices, internal server error",
+
+	// Calendar
+	"calendar.name":        "YouTube Live Schedule",
+	"calendar.url":         "Subscribe following URL in your calendar app:\n%s\nPlease use %s to revoke it.",
+	"calendar.usage":       "Please use %s to get calendar URL.",
+	"calendar.issue_fail":  "Failed to issue calendar URL, internal server error",
+	"calendar.revoked":     "Calendar URL revoked, please use %s to issue a new one.",
+	"calendar.revoke_fail": "Failed to revoke calendar URL, internal server error",
+
+	// Filter
+	"filter.usage":         "Please use %s to set filter.",
+	"filter.setup_fail":    "Filter setup on %s failed, internal server error",
+	"filter.show_fail":     "Filter show on %s failed, internal server error",
+	"filter.blacklist":     "blacklist:",
+	"filter.whitelist":     "whitelist:",
+	"filter.expression":    "expression:",
+	"filter.expr_invalid":  "Invalid filter expression: %s",
+	"filter.global":        "Chat-wide filters",
+	"filter.expr_prompt":   "Setup notify expression: %s\nInput %s to clear expression.",
+	"filter.test_title":    "Test on last %d videos, %s:",
+	"filter.test_summary":  "%d notified, %d suppressed.",
+	"filter.test_empty":    "No stored videos to test, %s.",
+	"filter.setup":         "Setup notify filter: %s",
+	"filter.list_prompt":   "Setup notify %s: %s\nSeperated by comma, input %s to clear filter.",
+	"filter.updated":       "Success! Filter updated.",
+	"filter.reply_too_old": "This message is too old.",
+
+	// Autorecorder
+	"autorecord.usage":       "Please use %s to set autorecorder.",
+	"autorecord.show_fail":   "Failed to show autorecords, internal server error",
+	"autorecord.list":        "You already set autorecorder on these channels:\n%s",
+	"autorecord.modify_fail": "Failed to modify autorecorder on %s, internal server error",
+	"autorecord.add_fail":    "Failed to add autorecorder to %s, internal server error",
+	"autorecord.added":       "Add autorecorder on %s",
+	"autorecord.removed":     "Remove autorecorder on %s",
+
+	// Recorder
+	"record.added":       "Add %s recorder",
+	"record.started":     "Start recording %s",
+	"record.recorded":    "%s recorded as\n%s",
+	"record.failed":      "Failed to record %s, check your recorder",
+	"record.internal":    "Record %s failed, internal server error",
+	"record.timeout":     "Record %s failed, connection timeout",
+	"record.status":      "Record request failed with status code %d, please check your recorder",
+	"record.unavailable": "Recorder unavailable for you",
+	"download.internal":  "Download request failed, internal server error",
+	"download.timeout":   "Download request failed, connection timeout",
+	"download.status":    "Download request failed with status code %d, please check your recorder",
+	"download.accepted":  "Download request has been accepted",
+	"download.done":      "%s downloaded as\n%s",
+
+	// Notice
+	"notice.status":          "Status",
+	"notice.upcoming":        "Upcoming",
+	"notice.live":            "Live",
+	"notice.completed":       "Completed",
+	"notice.scheduled_start": "Scheduled Start Time",
+	"notice.actual_start":    "Actual Start Time",
+	"notice.actual_end":      "Actual End Time",
+	"notice.duration":        "Duration",
+	"notice.viewers":         "Viewers",
+	"notice.now_live":        "%s is now live!",
+
+	// Restriction tags
+	"notice.members_only":      "Members Only",
+	"notice.age_restricted":    "Age Restricted",
+	"notice.region_restricted": "Region Restricted",
+
+	// Summary
+	"summary.title":   "Stream Ended",
+	"summary.peak":    "Peak Viewers",
+	"summary.views":   "Views",
+	"summary.likes":   "Likes",
+	"vod.available":   "VOD of %s is now available",
+	"vod.unavailable": "VOD of %s has been privated or removed",
+}
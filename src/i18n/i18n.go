@@ -0,0 +1,76 @@
+package i18n
+
+import "strings"
+
+// Catalog maps message keys to message formats.
+//
+// Formats are plain text, markup symbols should not be escaped.
+type Catalog map[string]string
+
+// Default is the fallback language when a message or language is missing.
+const Default = "en"
+
+var catalogs = map[string]Catalog{
+	"en":    en,
+	"ja":    ja,
+	"zh-TW": zhTW,
+}
+
+var names = map[string]string{
+	"en":    "English",
+	"ja":    "日本語",
+	"zh-TW": "繁體中文",
+}
+
+// Languages returns all supported language codes.
+func Languages() []string {
+	return []string{"en", "ja", "zh-TW"}
+}
+
+// Name returns the native name of the language.
+func Name(lang string) string {
+	if name, ok := names[lang]; ok {
+		return name
+	}
+	return names[Default]
+}
+
+// Lookup returns the message format of key in lang.
+// It falls back to default language, and then key itself.
+func Lookup(lang, key string) string {
+	if c, ok := catalogs[lang]; ok {
+		if msg, ok := c[key]; ok {
+			return msg
+		}
+	}
+
+	if msg, ok := catalogs[Default][key]; ok {
+		return msg
+	}
+
+	return key
+}
+
+// Match maps IETF language tag, e.g. Telegram `language_code`,
+// to a supported language code. Returns empty string if no match.
+func Match(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+
+	for _, lang := range Languages() {
+		if strings.ToLower(lang) == tag {
+			return lang
+		}
+	}
+
+	switch {
+	case tag == "zh-hant" || strings.HasPrefix(tag, "zh-hant-") ||
+		tag == "zh-hk" || tag == "zh-mo":
+		return "zh-TW"
+	case strings.HasPrefix(tag, "ja"):
+		return "ja"
+	case strings.HasPrefix(tag, "en"):
+		return "en"
+	}
+
+	return ""
+}
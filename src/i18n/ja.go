@@ -0,0 +1,196 @@
+package i18n
+
+var ja = Catalog{
+	// Common
+	"common.empty_params": "パラメータがありません。",
+	"common.na":           "N/A",
+	"common.internal":     "サーバー内部エラー",
+
+	// Relative time
+	"relative.now":    "今",
+	"relative.future": "%s後",
+	"relative.past":   "%s前",
+
+	// Buttons
+	"button.record":                "録画",
+	"button.filter":                "フィルター",
+	"button.remove":                "削除",
+	"button.cancel":                "キャンセル",
+	"button.blacklist":             "ブラックリスト",
+	"button.whitelist":             "ホワイトリスト",
+	"button.global_filter":         "チャット全体のフィルター",
+	"button.expression":            "フィルター式",
+	"button.test_filter":           "フィルターをテスト",
+	"button.back_channel_list":     "« チャンネル一覧へ戻る",
+	"button.back_operation_list":   "« 操作一覧へ戻る",
+	"button.back_filter_operation": "« フィルター操作へ戻る",
+
+	// Subscription
+	"action.subscribe":          "登録",
+	"action.remind":             "リマインド",
+	"subscribe.usage":           "%s で登録してください。",
+	"subscribe.success":         "%s %s に成功しました。",
+	"subscribe.failed.internal": "%s %s に失敗しました。\nサーバー内部エラー。",
+	"subscribe.failed.invalid":  "%s %s に失敗しました。\n無効なチャンネルID：%s。",
+	"subscribe.internal_error":  "%s の登録に失敗しました、サーバー内部エラー",
+	"channel.invalid":           "%s は有効なYouTubeチャンネルではありません",
+	"channel.not_subscribed":    "%s を登録していません",
+	"list.failed":               "登録チャンネルを表示できません。\nサーバー内部エラー。",
+	"list.header":               "登録済みのチャンネル：",
+	"list.operation":            "こちらです：%s\nこのチャンネルをどうしますか？",
+	"remove.confirm":            "本当に %s を削除しますか？",
+	"remove.done":               "登録を解除しました\n%s",
+	"remind.usage":              "%s でリマインダーを設定してください。",
+	"schedule.failed":           "配信予定を表示できません。\nサーバー内部エラー。",
+	"schedule.empty":            "予定されている配信はありません。",
+	"schedule.usage":            "%s で配信予定を表示してください。",
+	"schedule.title":            "配信スケジュール",
+	"schedule.all":              "今後の予定",
+	"schedule.today":            "今日",
+	"schedule.tomorrow":         "明日",
+	"schedule.week":             "今週",
+	"schedule.now_streaming":    "配信中",
+
+	// Weekdays
+	"weekday.0": "日",
+	"weekday.1": "月",
+	"weekday.2": "火",
+	"weekday.3": "水",
+	"weekday.4": "木",
+	"weekday.5": "金",
+	"weekday.6": "土",
+
+	// Timezone
+	"timezone.current":    "現在のタイムゾーン：%s\n%s で変更できます、例：%s。",
+	"timezone.reset":      "タイムゾーンを %s に戻しました",
+	"timezone.reset_fail": "タイムゾーンのリセットに失敗しました、サーバー内部エラー",
+	"timezone.invalid":    "%s は有効なIANAタイムゾーン名ではありません",
+	"timezone.set":        "タイムゾーンを %s に設定しました、現在時刻は %s です",
+	"timezone.set_fail":   "タイムゾーンの設定に失敗しました、サーバー内部エラー",
+
+	// Language
+	"language.current":  "現在の言語：%s\n%s で変更できます。\n対応言語：%s",
+	"language.invalid":  "%s は対応していない言語です",
+	"language.set":      "言語を %s に設定しました",
+	"language.set_fail": "言語の設定に失敗しました、サーバー内部エラー",
+
+	// Template
+	"template.current":    "現在のテンプレート：\n%s\n\n%s で設定、%s でプレビュー、%s でリセットできます。\n利用可能なフィールド：%s\n利用可能な関数：%s",
+	"template.default":    "デフォルトのレイアウトを使用しています。",
+	"template.invalid":    "無効なテンプレート：%s",
+	"template.set":        "テンプレートを更新しました、プレビュー：",
+	"template.set_fail":   "テンプレートの設定に失敗しました、サーバー内部エラー",
+	"template.reset":      "テンプレートをデフォルトのレイアウトに戻しました。",
+	"template.reset_fail": "テンプレートのリセットに失敗しました、サーバー内部エラー",
+
+	// Opt-out
+	"optout.usage":    "%s で制限付き配信をスキップできます。",
+	"optout.set_fail": "オプトアウトの設定に失敗しました、サーバー内部エラー",
+	"optout.notify":   "通知",
+	"optout.skip":     "スキップ",
+	"optout.skip_all": "スキップ（全チャンネル）",
+	"optout.members":  "メンバー限定",
+	"optout.age":      "年齢制限",
+	"optout.region":   "地域制限",
+
+	// Search
+	"search.title":       "%s に一致するチャンネル：",
+	"search.empty":       "%s に一致するチャンネルはありません。",
+	"search.failed":      "チャンネルの検索に失敗しました、サーバー内部エラー",
+	"search.subscribers": "登録者 %s 人",
+	"search.hidden":      "登録者数非公開",
+
+	// Guest
+	"guest.featuring": "🤝 ゲスト出演：%s",
+	"guest.current":   "ゲスト出演通知：%s\n%s で変更できます。",
+	"guest.on":        "オン",
+	"guest.off":       "オフ",
+	"guest.usage":     "%s でゲスト出演通知を設定してください。",
+	"guest.enabled":   "登録チャンネルがゲスト出演する配信も通知します。",
+	"guest.disabled":  "ゲスト出演の通知を停止しました。",
+	"guest.set_fail":  "ゲスト出演通知の設定に失敗しました、サーバー内部エラー",
+
+	// Photo
+	"photo.current":  "サムネイル付き通知：%s\n%s で変更できます。",
+	"photo.on":       "オン",
+	"photo.off":      "オフ",
+	"photo.usage":    "%s でサムネイル付き通知を設定してください。",
+	"photo.enabled":  "新しい通知はサムネイル画像付きで送信されます。",
+	"photo.disabled": "新しい通知はテキストメッセージで送信されます。",
+	"photo.set_fail": "サムネイル付き通知の設定に失敗しました、サーバー内部エラー",
+
+	// Calendar
+	"calendar.name":        "YouTube配信スケジュール",
+	"calendar.url":         "カレンダーアプリで以下のURLを購読してください：\n%s\n%s で無効化できます。",
+	"calendar.usage":       "%s でカレンダーURLを取得してください。",
+	"calendar.issue_fail":  "カレンダーURLの発行に失敗しました、サーバー内部エラー",
+	"calendar.revoked":     "カレンダーURLを無効化しました、%s で新しいURLを発行できます。",
+	"calendar.revoke_fail": "カレンダーURLの無効化に失敗しました、サーバー内部エラー",
+
+	// Filter
+	"filter.usage":         "%s でフィルターを設定してください。",
+	"filter.setup_fail":    "%s のフィルター設定に失敗しました、サーバー内部エラー",
+	"filter.show_fail":     "%s のフィルター表示に失敗しました、サーバー内部エラー",
+	"filter.blacklist":     "ブラックリスト：",
+	"filter.whitelist":     "ホワイトリスト：",
+	"filter.expression":    "フィルター式：",
+	"filter.expr_invalid":  "無効なフィルター式：%s",
+	"filter.global":        "チャット全体のフィルター",
+	"filter.expr_prompt":   "通知フィルター式の設定：%s\n%s でフィルター式をクリアします。",
+	"filter.test_title":    "最近の動画 %d 本でテスト、%s：",
+	"filter.test_summary":  "通知 %d 本、除外 %d 本。",
+	"filter.test_empty":    "テストできる動画がありません、%s。",
+	"filter.setup":         "通知フィルター設定：%s",
+	"filter.list_prompt":   "通知%sの設定：%s\nカンマ区切りで入力、%s でフィルターをクリアします。",
+	"filter.updated":       "成功！フィルターを更新しました。",
+	"filter.reply_too_old": "このメッセージは古すぎます。",
+
+	// Autorecorder
+	"autorecord.usage":       "%s で自動録画を設定してください。",
+	"autorecord.show_fail":   "自動録画の表示に失敗しました、サーバー内部エラー",
+	"autorecord.list":        "以下のチャンネルに自動録画を設定済みです：\n%s",
+	"autorecord.modify_fail": "%s の自動録画の変更に失敗しました、サーバー内部エラー",
+	"autorecord.add_fail":    "%s への自動録画の追加に失敗しました、サーバー内部エラー",
+	"autorecord.added":       "%s に自動録画を追加しました",
+	"autorecord.removed":     "%s の自動録画を削除しました",
+
+	// Recorder
+	"record.added":       "%s の録画を追加しました",
+	"record.started":     "%s の録画を開始しました",
+	"record.recorded":    "%s を録画しました\n%s",
+	"record.failed":      "%s の録画に失敗しました、レコーダーを確認してください",
+	"record.internal":    "%s の録画に失敗しました、サーバー内部エラー",
+	"record.timeout":     "%s の録画に失敗しました、接続タイムアウト",
+	"record.status":      "録画リクエストがステータスコード %d で失敗しました、レコーダーを確認してください",
+	"record.unavailable": "レコーダーを利用できません",
+	"download.internal":  "ダウンロードリクエストに失敗しました、サーバー内部エラー",
+	"download.timeout":   "ダウンロードリクエストに失敗しました、接続タイムアウト",
+	"download.status":    "ダウンロードリクエストがステータスコード %d で失敗しました、レコーダーを確認してください",
+	"download.accepted":  "ダウンロードリクエストを受け付けました",
+	"download.done":      "%s をダウンロードしました\n%s",
+
+	// Notice
+	"notice.status":          "ステータス",
+	"notice.upcoming":        "配信予定",
+	"notice.live":            "配信中",
+	"notice.completed":       "配信終了",
+	"notice.scheduled_start": "開始予定時刻",
+	"notice.actual_start":    "開始時刻",
+	"notice.actual_end":      "終了時刻",
+	"notice.duration":        "配信時間",
+	"notice.viewers":         "同時視聴者数",
+	"notice.now_live":        "%s が配信を開始しました！",
+
+	// Restriction tags
+	"notice.members_only":      "メンバー限定",
+	"notice.age_restricted":    "年齢制限",
+	"notice.region_restricted": "地域制限",
+
+	// Summary
+	"summary.title":   "配信終了",
+	"summary.peak":    "最大同時視聴者数",
+	"summary.views":   "再生回数",
+	"summary.likes":   "高評価",
+	"vod.available":   "%s のアーカイブが公開されました",
+	"vod.unavailable": "%s のアーカイブは非公開または削除されました",
+}
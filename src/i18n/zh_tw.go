@@ -0,0 +1,196 @@
+package i18n
+
+var zhTW = Catalog{
+	// Common
+	"common.empty_params": "缺少參數。",
+	"common.na":           "N/A",
+	"common.internal":     "伺服器內部錯誤",
+
+	// Relative time
+	"relative.now":    "現在",
+	"relative.future": "%s後",
+	"relative.past":   "%s前",
+
+	// Buttons
+	"button.record":                "錄影",
+	"button.filter":                "過濾器",
+	"button.remove":                "移除",
+	"button.cancel":                "取消",
+	"button.blacklist":             "黑名單",
+	"button.whitelist":             "白名單",
+	"button.global_filter":         "全聊天室過濾器",
+	"button.expression":            "過濾運算式",
+	"button.test_filter":           "測試過濾器",
+	"button.back_channel_list":     "« 回到頻道列表",
+	"button.back_operation_list":   "« 回到操作列表",
+	"button.back_filter_operation": "« 回到過濾器操作",
+
+	// Subscription
+	"action.subscribe":          "訂閱",
+	"action.remind":             "提醒",
+	"subscribe.usage":           "請使用 %s 訂閱。",
+	"subscribe.success":         "%s %s 成功。",
+	"subscribe.failed.internal": "%s %s 失敗。\n伺服器內部錯誤。",
+	"subscribe.failed.invalid":  "%s %s 失敗。\n無效的頻道 ID：%s。",
+	"subscribe.internal_error":  "訂閱 %s 失敗，伺服器內部錯誤",
+	"channel.invalid":           "%s 不是有效的 YouTube 頻道",
+	"channel.not_subscribed":    "你尚未訂閱 %s",
+	"list.failed":               "無法列出已訂閱頻道。\n伺服器內部錯誤。",
+	"list.header":               "你已訂閱以下頻道：",
+	"list.operation":            "在這裡：%s\n你想對這個頻道做什麼？",
+	"remove.confirm":            "確定要移除 %s 嗎？",
+	"remove.done":               "你已取消訂閱\n%s",
+	"remind.usage":              "請使用 %s 設定影片提醒。",
+	"schedule.failed":           "無法列出直播排程。\n伺服器內部錯誤。",
+	"schedule.empty":            "沒有即將開始的直播。",
+	"schedule.usage":            "請使用 %s 列出直播排程。",
+	"schedule.title":            "直播排程",
+	"schedule.all":              "即將開始",
+	"schedule.today":            "今天",
+	"schedule.tomorrow":         "明天",
+	"schedule.week":             "本週",
+	"schedule.now_streaming":    "正在直播",
+
+	// Weekdays
+	"weekday.0": "週日",
+	"weekday.1": "週一",
+	"weekday.2": "週二",
+	"weekday.3": "週三",
+	"weekday.4": "週四",
+	"weekday.5": "週五",
+	"weekday.6": "週六",
+
+	// Timezone
+	"timezone.current":    "目前時區：%s\n請使用 %s 變更，例如 %s。",
+	"timezone.reset":      "時區已重設為 %s",
+	"timezone.reset_fail": "重設時區失敗，伺服器內部錯誤",
+	"timezone.invalid":    "%s 不是有效的 IANA 時區名稱",
+	"timezone.set":        "時區已設定為 %s，目前時間為 %s",
+	"timezone.set_fail":   "設定時區失敗，伺服器內部錯誤",
+
+	// Language
+	"language.current":  "目前語言：%s\n請使用 %s 變更。\n支援的語言：%s",
+	"language.invalid":  "%s 不是支援的語言",
+	"language.set":      "語言已設定為 %s",
+	"language.set_fail": "設定語言失敗，伺服器內部錯誤",
+
+	// Template
+	"template.current":    "目前的範本：\n%s\n\n請使用 %s 設定、%s 預覽、%s 重設。\n可用欄位：%s\n可用函式：%s",
+	"template.default":    "目前使用預設版面。",
+	"template.invalid":    "無效的範本：%s",
+	"template.set":        "範本已更新，預覽：",
+	"template.set_fail":   "設定範本失敗，伺服器內部錯誤",
+	"template.reset":      "範本已重設為預設版面。",
+	"template.reset_fail": "重設範本失敗，伺服器內部錯誤",
+
+	// Opt-out
+	"optout.usage":    "請使用 %s 略過受限制的直播。",
+	"optout.set_fail": "設定略過失敗，伺服器內部錯誤",
+	"optout.notify":   "通知",
+	"optout.skip":     "略過",
+	"optout.skip_all": "略過（所有頻道）",
+	"optout.members":  "會員限定",
+	"optout.age":      "年齡限制",
+	"optout.region":   "地區限制",
+
+	// Search
+	"search.title":       "符合 %s 的頻道：",
+	"search.empty":       "沒有符合 %s 的頻道。",
+	"search.failed":      "搜尋頻道失敗，伺服器內部錯誤",
+	"search.subscribers": "%s 位訂閱者",
+	"search.hidden":      "訂閱人數已隱藏",
+
+	// Guest
+	"guest.featuring": "🤝 客串：%s",
+	"guest.current":   "客串通知：%s\n請使用 %s 變更。",
+	"guest.on":        "開啟",
+	"guest.off":       "關閉",
+	"guest.usage":     "請使用 %s 設定客串通知。",
+	"guest.enabled":   "將通知已訂閱頻道客串演出的直播。",
+	"guest.disabled":  "將不再通知客串演出。",
+	"guest.set_fail":  "設定客串通知失敗，伺服器內部錯誤",
+
+	// Photo
+	"photo.current":  "縮圖通知：%s\n請使用 %s 變更。",
+	"photo.on":       "開啟",
+	"photo.off":      "關閉",
+	"photo.usage":    "請使用 %s 設定縮圖通知。",
+	"photo.enabled":  "新的通知將以縮圖照片傳送。",
+	"photo.disabled": "新的通知將以文字訊息傳送。",
+	"photo.set_fail": "設定縮圖通知失敗，伺服器內部錯誤",
+
+	// Calendar
+	"calendar.name":        "YouTube 直播排程",
+	"calendar.url":         "請在行事曆應用程式中訂閱以下網址：\n%s\n請使用 %s 撤銷。",
+	"calendar.usage":       "請使用 %s 取得行事曆網址。",
+	"calendar.issue_fail":  "發行行事曆網址失敗，伺服器內部錯誤",
+	"calendar.revoked":     "行事曆網址已撤銷，請使用 %s 發行新網址。",
+	"calendar.revoke_fail": "撤銷行事曆網址失敗，伺服器內部錯誤",
+
+	// Filter
+	"filter.usage":         "請使用 %s 設定過濾器。",
+	"filter.setup_fail":    "設定 %s 的過濾器失敗，伺服器內部錯誤",
+	"filter.show_fail":     "顯示 %s 的過濾器失敗，伺服器內部錯誤",
+	"filter.blacklist":     "黑名單：",
+	"filter.whitelist":     "白名單：",
+	"filter.expression":    "過濾運算式：",
+	"filter.expr_invalid":  "無效的過濾運算式：%s",
+	"filter.global":        "全聊天室過濾器",
+	"filter.expr_prompt":   "設定通知過濾運算式：%s\n輸入 %s 清除運算式。",
+	"filter.test_title":    "以最近 %d 部影片測試，%s：",
+	"filter.test_summary":  "通知 %d 部，過濾 %d 部。",
+	"filter.test_empty":    "沒有可測試的影片，%s。",
+	"filter.setup":         "設定通知過濾器：%s",
+	"filter.list_prompt":   "設定通知%s：%s\n以逗號分隔，輸入 %s 清除過濾器。",
+	"filter.updated":       "成功！過濾器已更新。",
+	"filter.reply_too_old": "這則訊息已過期。",
+
+	// Autorecorder
+	"autorecord.usage":       "請使用 %s 設定自動錄影。",
+	"autorecord.show_fail":   "顯示自動錄影失敗，伺服器內部錯誤",
+	"autorecord.list":        "你已在以下頻道設定自動錄影：\n%s",
+	"autorecord.modify_fail": "修改 %s 的自動錄影失敗，伺服器內部錯誤",
+	"autorecord.add_fail":    "新增 %s 的自動錄影失敗，伺服器內部錯誤",
+	"autorecord.added":       "已在 %s 新增自動錄影",
+	"autorecord.removed":     "已移除 %s 的自動錄影",
+
+	// Recorder
+	"record.added":       "已新增 %s 錄影",
+	"record.started":     "開始錄影 %s",
+	"record.recorded":    "%s 已錄影為\n%s",
+	"record.failed":      "錄影 %s 失敗，請檢查你的錄影器",
+	"record.internal":    "錄影 %s 失敗，伺服器內部錯誤",
+	"record.timeout":     "錄影 %s 失敗，連線逾時",
+	"record.status":      "錄影請求失敗，狀態碼 %d，請檢查你的錄影器",
+	"record.unavailable": "你無法使用錄影器",
+	"download.internal":  "下載請求失敗，伺服器內部錯誤",
+	"download.timeout":   "下載請求失敗，連線逾時",
+	"download.status":    "下載請求失敗，狀態碼 %d，請檢查你的錄影器",
+	"download.accepted":  "下載請求已接受",
+	"download.done":      "%s 已下載為\n%s",
+
+	// Notice
+	"notice.status":          "狀態",
+	"notice.upcoming":        "即將開始",
+	"notice.live":            "直播中",
+	"notice.completed":       "已結束",
+	"notice.scheduled_start": "預定開始時間",
+	"notice.actual_start":    "實際開始時間",
+	"notice.actual_end":      "實際結束時間",
+	"notice.duration":        "直播長度",
+	"notice.viewers":         "同時觀看人數",
+	"notice.now_live":        "%s 開始直播了！",
+
+	// Restriction tags
+	"notice.members_only":      "會員限定",
+	"notice.age_restricted":    "年齡限制",
+	"notice.region_restricted": "地區限制",
+
+	// Summary
+	"summary.title":   "直播結束",
+	"summary.peak":    "最高同時觀看人數",
+	"summary.views":   "觀看次數",
+	"summary.likes":   "喜歡",
+	"vod.available":   "%s 的存檔已經公開",
+	"vod.unavailable": "%s 的存檔已設為私人或被移除",
+}
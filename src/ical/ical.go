@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar is an iCalendar (RFC 5545) object of events.
+type Calendar struct {
+	// Name is the display name of calendar.
+	Name string
+	// Refresh suggests how often clients should poll the calendar.
+	Refresh time.Duration
+	Events  []Event
+}
+
+// Event is a VEVENT component.
+type Event struct {
+	// UID must be globally unique & stable, clients update events by it.
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start, End  time.Time
+	// Modified is the last modified time of event.
+	Modified time.Time
+	// Sequence is the revision of event, increased when it's rescheduled.
+	Sequence int
+}
+
+const (
+	dateTimeLayout = "20060102T150405Z"
+	// Content lines should not be longer than 75 octets, excluding line break.
+	maxLineLength = 75
+)
+
+// Encode encodes calendar as iCalendar text.
+func (c Calendar) Encode() []byte {
+	var buf bytes.Buffer
+	stamp := time.Now()
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//tgbot-youtube-notifier//ical//EN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if c.Refresh > 0 {
+		writeLine(&buf, "REFRESH-INTERVAL;VALUE=DURATION:"+formatDuration(c.Refresh))
+		writeLine(&buf, "X-PUBLISHED-TTL:"+formatDuration(c.Refresh))
+	}
+
+	for _, e := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+escapeText(e.UID))
+		writeLine(&buf, "DTSTAMP:"+formatDateTime(stamp))
+		writeLine(&buf, "DTSTART:"+formatDateTime(e.Start))
+		if !e.End.IsZero() {
+			writeLine(&buf, "DTEND:"+formatDateTime(e.End))
+		}
+		if !e.Modified.IsZero() {
+			writeLine(&buf, "LAST-MODIFIED:"+formatDateTime(e.Modified))
+		}
+		writeLine(&buf, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+		writeLine(&buf, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.URL != "" {
+			writeLine(&buf, "URL:"+e.URL)
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// writeLine writes a content line, folded into multiple lines if too long.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineLength
+
+	for len(line) > limit {
+		// Never split a UTF-8 sequence.
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+
+		buf.WriteString(line[:i])
+		buf.WriteString("\r\n ")
+		line = line[i:]
+
+		// Continuation lines start with a space.
+		limit = maxLineLength - 1
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// formatDuration formats d as a RFC 5545 duration, e.g. PT15M.
+func formatDuration(d time.Duration) string {
+	var b strings.Builder
+	b.WriteString("PT")
+
+	if h := int(d.Hours()); h > 0 {
+		b.WriteString(strconv.Itoa(h) + "H")
+	}
+	if m := int(d.Minutes()) % 60; m > 0 {
+		b.WriteString(strconv.Itoa(m) + "M")
+	}
+	if s := int(d.Seconds()) % 60; s > 0 || b.Len() == 2 {
+		b.WriteString(strconv.Itoa(s) + "S")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,192 @@
+// Package logging writes structured logs as JSON lines, with correlation IDs
+// carried by context, so one notification can be traced end to end.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Levels of log lines.
+const (
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+	LevelFatal   = "fatal"
+)
+
+// output is the shared destination of loggers.
+type output struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+var std = &output{w: os.Stderr}
+
+// SetOutput sets the destination of every logger, default is stderr.
+func SetOutput(w io.Writer) {
+	std.mutex.Lock()
+	defer std.mutex.Unlock()
+
+	std.w = w
+}
+
+// Logger writes log lines with fields, it's immutable & safe to share.
+type Logger struct {
+	// fields are key value pairs.
+	fields []interface{}
+}
+
+// With returns a logger with key value pairs as fields.
+func With(kv ...interface{}) Logger {
+	return Logger{}.With(kv...)
+}
+
+// With returns a copy of logger with key value pairs appended.
+func (l Logger) With(kv ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+
+	return Logger{fields: fields}
+}
+
+// Info logs msg with key value pairs.
+func (l Logger) Info(msg string, kv ...interface{}) { l.log(LevelInfo, msg, kv) }
+
+// Warning logs msg with key value pairs, for failures of external services.
+func (l Logger) Warning(msg string, kv ...interface{}) { l.log(LevelWarning, msg, kv) }
+
+// Error logs msg with key value pairs, for failures of server itself.
+func (l Logger) Error(msg string, kv ...interface{}) { l.log(LevelError, msg, kv) }
+
+// Fatal logs msg with key value pairs, then exits the program.
+func (l Logger) Fatal(msg string, kv ...interface{}) {
+	l.log(LevelFatal, msg, kv)
+	exit(1)
+}
+
+// exit is replaced by tests.
+var exit = os.Exit
+
+// Writer returns a writer which logs every write as a line at level,
+// for redirecting std log.
+func (l Logger) Writer(level string) io.Writer {
+	return writer{logger: l, level: level}
+}
+
+type writer struct {
+	logger Logger
+	level  string
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	w.logger.log(w.level, strings.TrimRight(string(p), "\n"), nil)
+	return len(p), nil
+}
+
+func (l Logger) log(level, msg string, kv []interface{}) {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+	writeField(&b, "time", time.Now().UTC().Format(time.RFC3339Nano))
+	b.WriteByte(',')
+	writeField(&b, "level", level)
+	b.WriteByte(',')
+	writeField(&b, "msg", msg)
+
+	if _, file, line, ok := runtime.Caller(2); ok {
+		b.WriteByte(',')
+		writeField(&b, "caller", fmt.Sprintf("%s:%d", filepath.Base(file), line))
+	}
+
+	for _, fields := range [][]interface{}{l.fields, kv} {
+		for i := 0; i < len(fields); i += 2 {
+			key := fmt.Sprint(fields[i])
+
+			var value interface{} = "(missing)"
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+
+			b.WriteByte(',')
+			writeField(&b, key, value)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	std.mutex.Lock()
+	defer std.mutex.Unlock()
+
+	std.w.Write(b.Bytes())
+}
+
+func writeField(b *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteByte(':')
+
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case fmt.Stringer:
+		value = v.String()
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+	b.Write(v)
+}
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	correlationIDKey
+)
+
+// NewID returns a random correlation ID.
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID returns a copy of ctx carries id,
+// and its logger has id as field "cid".
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey, id)
+	return NewContext(ctx, "cid", id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, empty if none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// NewContext returns a copy of ctx carries its logger with key value pairs appended.
+func NewContext(ctx context.Context, kv ...interface{}) context.Context {
+	return context.WithValue(ctx, loggerKey, FromContext(ctx).With(kv...))
+}
+
+// FromContext returns the logger carried by ctx, or a logger without fields.
+func FromContext(ctx context.Context) Logger {
+	l, _ := ctx.Value(loggerKey).(Logger)
+	return l
+}
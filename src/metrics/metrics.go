@@ -0,0 +1,291 @@
+// Package metrics collects counters, gauges & histograms, and exposes them
+// in Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets are the default histogram buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry is a set of metrics.
+type Registry struct {
+	mutex   sync.Mutex
+	metrics []*vec
+	names   map[string]bool
+}
+
+// Default is the registry used by package level constructors.
+var Default = NewRegistry()
+
+// NewRegistry returns a pointer to a new empty `Registry`.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(v *vec) *vec {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.names[v.name] {
+		panic("metrics: duplicate metric " + v.name)
+	}
+
+	r.names[v.name] = true
+	r.metrics = append(r.metrics, v)
+	return v
+}
+
+// WriteTo writes every metric in Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	metrics := append([]*vec(nil), r.metrics...)
+	r.mutex.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+
+	var b strings.Builder
+	for _, v := range metrics {
+		v.write(&b)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves metrics of registry.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// Handler serves metrics of the default registry.
+func Handler() http.Handler {
+	return Default
+}
+
+// Counter is a monotonically increasing value partitioned by labels.
+type Counter struct{ *vec }
+
+// NewCounter registers a counter with label names in the default registry.
+func NewCounter(name, help string, labels ...string) Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter registers a counter with label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) Counter {
+	return Counter{r.register(newVec(name, help, "counter", labels, nil))}
+}
+
+// Inc increases counter of label values by 1.
+func (c Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases counter of label values by delta, which must not be negative.
+func (c Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counter " + c.name + " can not decrease")
+	}
+
+	c.update(labelValues, func(s *series) { s.value += delta })
+}
+
+// Gauge is a value which can go up & down partitioned by labels.
+type Gauge struct{ *vec }
+
+// NewGauge registers a gauge with label names in the default registry.
+func NewGauge(name, help string, labels ...string) Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewGauge registers a gauge with label names.
+func (r *Registry) NewGauge(name, help string, labels ...string) Gauge {
+	return Gauge{r.register(newVec(name, help, "gauge", labels, nil))}
+}
+
+// Set sets gauge of label values to value.
+func (g Gauge) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(s *series) { s.value = value })
+}
+
+// Add adds delta to gauge of label values.
+func (g Gauge) Add(delta float64, labelValues ...string) {
+	g.update(labelValues, func(s *series) { s.value += delta })
+}
+
+// Inc increases gauge of label values by 1.
+func (g Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec decreases gauge of label values by 1.
+func (g Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Histogram counts observations in buckets partitioned by labels.
+type Histogram struct{ *vec }
+
+// NewHistogram registers a histogram with buckets & label names in the default registry.
+func NewHistogram(name, help string, buckets []float64, labels ...string) Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram registers a histogram with buckets & label names.
+// Buckets are upper bounds sorted ascending, DefBuckets if nil.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+
+	return Histogram{r.register(newVec(name, help, "histogram", labels, buckets))}
+}
+
+// Observe adds value to histogram of label values.
+func (h Histogram) Observe(value float64, labelValues ...string) {
+	h.update(labelValues, func(s *series) {
+		for i, upper := range h.buckets {
+			if value <= upper {
+				s.buckets[i]++
+			}
+		}
+		s.count++
+		s.value += value
+	})
+}
+
+// Since observes the seconds elapsed since t.
+func (h Histogram) Since(t time.Time, labelValues ...string) {
+	h.Observe(time.Since(t).Seconds(), labelValues...)
+}
+
+// vec is a metric family, which has a series for each combination of label values.
+type vec struct {
+	name, help, kind string
+	labels           []string
+	buckets          []float64
+
+	mutex  sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	// value is the sum of observations for histograms.
+	value   float64
+	count   uint64
+	buckets []uint64
+}
+
+func newVec(name, help, kind string, labels []string, buckets []float64) *vec {
+	return &vec{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+}
+
+func (v *vec) update(labelValues []string, f func(s *series)) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{
+			labelValues: append([]string(nil), labelValues...),
+			buckets:     make([]uint64, len(v.buckets)),
+		}
+		v.series[key] = s
+	}
+
+	f(s)
+}
+
+func (v *vec) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n", v.name, escape(v.help, false))
+	fmt.Fprintf(b, "# TYPE %s %s\n", v.name, v.kind)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Metrics without labels are exposed as zero before any update.
+	if len(keys) == 0 && len(v.labels) == 0 {
+		v.series[""] = &series{buckets: make([]uint64, len(v.buckets))}
+		keys = append(keys, "")
+	}
+
+	for _, k := range keys {
+		s := v.series[k]
+		labels := v.formatLabels(s.labelValues)
+
+		if v.kind != "histogram" {
+			fmt.Fprintf(b, "%s%s %s\n", v.name, labels(""), formatFloat(s.value))
+			continue
+		}
+
+		for i, upper := range v.buckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", v.name, labels(formatFloat(upper)), s.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", v.name, labels("+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", v.name, labels(""), formatFloat(s.value))
+		fmt.Fprintf(b, "%s_count%s %d\n", v.name, labels(""), s.count)
+	}
+}
+
+// formatLabels returns a function formats label pairs, with "le" label if it's not empty.
+func (v *vec) formatLabels(labelValues []string) func(le string) string {
+	return func(le string) string {
+		var pairs []string
+		for i, name := range v.labels {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape(labelValues[i], true)))
+		}
+		if le != "" {
+			pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+		}
+
+		if len(pairs) == 0 {
+			return ""
+		}
+		return "{" + strings.Join(pairs, ",") + "}"
+	}
+}
+
+func escape(s string, quote bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quote {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	} else if math.IsInf(f, -1) {
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+
+	sends := r.NewCounter("tg_sends_total", "Telegram sends by method & result.", "method", "result")
+	subscribed := r.NewGauge("hub_subscribed", "Subscribed channels.")
+	latency := r.NewHistogram("yt_request_seconds", "YouTube API latency.", []float64{.1, 1}, "endpoint")
+	r.NewCounter("recorder_requests_total", "Recorder requests by result.", "result")
+
+	sends.Inc("sendMessage", "ok")
+	sends.Add(2, "sendMessage", "ok")
+	sends.Inc("editMessageText", "error")
+	subscribed.Set(5)
+	subscribed.Dec()
+	latency.Observe(.05, "videos")
+	latency.Observe(.5, "videos")
+	latency.Observe(3, "videos")
+
+	want := `# HELP hub_subscribed Subscribed channels.
+# TYPE hub_subscribed gauge
+hub_subscribed 4
+# HELP recorder_requests_total Recorder requests by result.
+# TYPE recorder_requests_total counter
+# HELP tg_sends_total Telegram sends by method & result.
+# TYPE tg_sends_total counter
+tg_sends_total{method="editMessageText",result="error"} 1
+tg_sends_total{method="sendMessage",result="ok"} 3
+# HELP yt_request_seconds YouTube API latency.
+# TYPE yt_request_seconds histogram
+yt_request_seconds_bucket{endpoint="videos",le="0.1"} 1
+yt_request_seconds_bucket{endpoint="videos",le="1"} 2
+yt_request_seconds_bucket{endpoint="videos",le="+Inf"} 3
+yt_request_seconds_sum{endpoint="videos"} 3.55
+yt_request_seconds_count{endpoint="videos"} 3
+`
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+
+	if got := b.String(); got != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteToEscape(t *testing.T) {
+	r := NewRegistry()
+
+	errors := r.NewCounter("errors_total", "Errors by message,\nwith \\ in help.", "error")
+	errors.Inc(`say "hi"` + "\n" + `C:\`)
+
+	want := `# HELP errors_total Errors by message,\nwith \\ in help.
+# TYPE errors_total counter
+errors_total{error="say \"hi\"\nC:\\"} 1
+`
+
+	var b strings.Builder
+	r.WriteTo(&b)
+
+	if got := b.String(); got != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("up", "Whether the service is up.").Set(1)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want Prometheus text format", ct)
+	}
+
+	want := "# HELP up Whether the service is up.\n# TYPE up gauge\nup 1\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(r *Registry)
+	}{
+		{"duplicate", func(r *Registry) {
+			r.NewCounter("dup", "")
+			r.NewGauge("dup", "")
+		}},
+		{"label values", func(r *Registry) {
+			r.NewCounter("c", "", "a", "b").Inc("a")
+		}},
+		{"negative counter", func(r *Registry) {
+			r.NewCounter("c", "").Add(-1)
+		}},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", tt.name)
+				}
+			}()
+
+			tt.f(NewRegistry())
+		}()
+	}
+}
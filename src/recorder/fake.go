@@ -0,0 +1,54 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Fake is a local recorder service which accepts & records every request.
+type Fake struct {
+	Recorder
+
+	server   *httptest.Server
+	mutex    sync.Mutex
+	requests []map[string]interface{}
+}
+
+// NewFake starts a fake recorder service of chat.
+func NewFake(chatID int64) *Fake {
+	f := &Fake{}
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		data := make(map[string]interface{})
+		if err := json.Unmarshal(body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.mutex.Lock()
+		f.requests = append(f.requests, data)
+		f.mutex.Unlock()
+	}))
+
+	f.Recorder = Recorder{ChatID: chatID, Url: f.server.URL, Token: "fake"}
+
+	return f
+}
+
+// Requests returns received request bodies in order.
+func (f *Fake) Requests() []map[string]interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]map[string]interface{}(nil), f.requests...)
+}
+
+// Close shuts down the fake recorder service.
+func (f *Fake) Close() {
+	f.server.Close()
+}
@@ -2,10 +2,21 @@ package recorder
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
+)
+
+var requests = metrics.NewCounter(
+	"notifier_recorder_requests_total",
+	"Recorder requests by action & outcome.",
+	"action", "outcome",
 )
 
 type Recorder struct {
@@ -14,27 +25,65 @@ type Recorder struct {
 	Token  string
 }
 
-func (rc Recorder) Record(callbackUrl string, data map[string]interface{}) (*http.Response, error) {
+func (rc Recorder) Record(ctx context.Context, callbackUrl string, data map[string]interface{}) (*http.Response, error) {
 	data["callback"] = callbackUrl
 	data["chatID"] = rc.ChatID
 	data["action"] = "record"
-	return rc.request(data)
+	return rc.request(ctx, data)
 }
 
-func (rc Recorder) Download(callbackUrl string, data map[string]interface{}) (*http.Response, error) {
+func (rc Recorder) Download(ctx context.Context, callbackUrl string, data map[string]interface{}) (*http.Response, error) {
 	data["callback"] = callbackUrl
 	data["chatID"] = rc.ChatID
 	data["action"] = "download"
-	return rc.request(data)
+	return rc.request(ctx, data)
+}
+
+// Ping checks recorder is reachable, any response below 500 counts.
+func (rc Recorder) Ping() error {
+	client := http.Client{Timeout: 5 * time.Second}
+
+	resp, err := client.Head(rc.Url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("recorder responds %s", resp.Status)
+	}
+
+	return nil
 }
 
-func (rc Recorder) request(data map[string]interface{}) (*http.Response, error) {
+// request posts data to recorder, with correlation ID of ctx as header "X-Correlation-ID".
+func (rc Recorder) request(ctx context.Context, data map[string]interface{}) (resp *http.Response, err error) {
+	defer func() {
+		outcome := "ok"
+		if e, ok := err.(*url.Error); ok && e.Timeout() {
+			outcome = "timeout"
+		} else if err != nil {
+			outcome = "error"
+		} else if resp.StatusCode != http.StatusOK {
+			outcome = "status"
+		}
+
+		requests.Inc(fmt.Sprint(data["action"]), outcome)
+
+		log := logging.FromContext(ctx).With("action", data["action"], "chatID", rc.ChatID, "outcome", outcome)
+		if err != nil {
+			log.Warning("Recorder request failed", "error", err)
+		} else {
+			log.Info("Recorder requested", "status", resp.StatusCode)
+		}
+	}()
+
 	b, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", rc.Url, bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, "POST", rc.Url, bytes.NewReader(b))
 	if err != nil {
 		return nil, err
 	}
@@ -42,12 +91,15 @@ func (rc Recorder) request(data map[string]interface{}) (*http.Response, error)
 	// Add request header
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", rc.Token))
+	if id := logging.CorrelationID(ctx); id != "" {
+		req.Header.Add("X-Correlation-ID", id)
+	}
 
 	// Setup request timeout
 	client := http.Client{Timeout: 5 * time.Second}
 
 	// Send record request to recorder
-	resp, err := client.Do(req)
+	resp, err = client.Do(req)
 	if err != nil {
 		return nil, err
 	}
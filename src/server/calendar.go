@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ical"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+)
+
+const (
+	calendarPathPrefix = "/calendar/"
+	calendarRefresh    = 15 * time.Minute
+	// Lives have no end time until completed, assume a typical length.
+	calendarEventLength = time.Hour
+)
+
+// calendarURL returns the ICS feed URL of token.
+func (s *Server) calendarURL(token string) string {
+	return fmt.Sprintf("http://%s%s%s.ics", s.CallbackUrl(), calendarPathPrefix, token)
+}
+
+func newCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// calendarFeedHandler serves upcoming lives of a chat as an iCalendar feed.
+func (s *Server) calendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := logging.WithCorrelationID(r.Context(), logging.NewID())
+	log := logging.FromContext(ctx)
+
+	token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, calendarPathPrefix), ".ics")
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	chatID, err := s.db.getCalendarChatID(token)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		log.Error("Database error", "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var results []struct {
+		vID, vTitle string
+		chTitle     string
+		vStartTime  int64
+	}
+
+	err = s.db.queryResults(
+		&results,
+		func(rows *sql.Rows, dest interface{}) error {
+			res := dest.(*struct {
+				vID, vTitle string
+				chTitle     string
+				vStartTime  int64
+			})
+			return rows.Scan(&res.vID, &res.vTitle, &res.chTitle, &res.vStartTime)
+		},
+		"SELECT videos.id, videos.title, videos.channelTitle, videos.startTime "+
+			"FROM notices INNER JOIN videos ON notices.videoID = videos.id "+
+			"WHERE notices.chatID = ? ORDER BY videos.startTime;",
+		chatID,
+	)
+
+	if err != nil {
+		log.Error("Database error", "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	lc := s.chatLocale(ctx, chatID)
+	cal := ical.Calendar{
+		Name:    lc.raw("calendar.name"),
+		Refresh: calendarRefresh,
+	}
+
+	for _, res := range results {
+		start := time.Unix(res.vStartTime, 0)
+
+		sequence, modified, err := s.db.getCalendarEvent(res.vID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("%s@%s", res.vID, s.Host),
+			Summary:     res.vTitle,
+			Description: fmt.Sprintf("%s\n%s", res.chTitle, ytVideoURLPrefix+res.vID),
+			URL:         ytVideoURLPrefix + res.vID,
+			Start:       start,
+			End:         start.Add(calendarEventLength),
+			Modified:    modified,
+			Sequence:    sequence,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(cal.Encode())
+}
+
+// calendarHandler issues or revokes ICS feed URL of chat.
+func (s *Server) calendarHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	if len(elements) > 1 {
+		if elements[1] != "revoke" {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+				lc.text("calendar.usage"),
+				tgbot.InlineCode(tgbot.EscapeText("/calendar [revoke]")),
+			))
+			return
+		}
+
+		if _, err := s.db.Exec("DELETE FROM calendars WHERE chatID = ?;", chatID); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("calendar.revoke_fail"))
+			return
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("calendar.revoked"),
+			tgbot.InlineCode(tgbot.EscapeText("/calendar")),
+		))
+		return
+	}
+
+	token, err := s.db.getCalendarToken(chatID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("calendar.issue_fail"))
+		return
+	}
+
+	// Issue a new token if the chat has none.
+	if token == "" {
+		if token, err = newCalendarToken(); err != nil {
+			log.Error("Failed to issue calendar token", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("calendar.issue_fail"))
+			return
+		}
+
+		if _, err := s.db.Exec(
+			"INSERT INTO calendars (chatID, token) VALUES (?, ?);",
+			chatID, token,
+		); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("calendar.issue_fail"))
+			return
+		}
+	}
+
+	msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+		lc.text("calendar.url"),
+		tgbot.InlineCode(tgbot.EscapeText(s.calendarURL(token))),
+		tgbot.InlineCode(tgbot.EscapeText("/calendar revoke")),
+	))
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"database/sql"
 	"reflect"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql" // mysql driver
@@ -74,6 +75,104 @@ func newDatabase(dataSourceName string) (*database, error) {
 		return nil, err
 	}
 
+	// Create table to save chat timezones
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS timezones (" +
+		"chatID BIGINT PRIMARY KEY, name VARCHAR(255));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save chat languages
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS languages (" +
+		"chatID BIGINT PRIMARY KEY, lang VARCHAR(16));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save chat notification message templates
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS templates (" +
+		"chatID BIGINT PRIMARY KEY, content TEXT);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save statistics sampled during lives
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS streamStats (" +
+		"videoID VARCHAR(255) PRIMARY KEY, peakViewers BIGINT);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save viewers time series of lives
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS viewers (" +
+		"videoID VARCHAR(255), time BIGINT, count BIGINT, PRIMARY KEY (videoID, time));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save summaries waiting for VOD follow-up
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS summaries (" +
+		"videoID VARCHAR(255), chatID BIGINT, messageID INT, endTime BIGINT, PRIMARY KEY (videoID, chatID));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save chats which prefer thumbnail photo notices
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS photoChats (" +
+		"chatID BIGINT PRIMARY KEY);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save notices which are sent as photos
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS photoNotices (" +
+		"videoID VARCHAR(255), chatID BIGINT, PRIMARY KEY (videoID, chatID));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save chat ICS feed tokens
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS calendars (" +
+		"chatID BIGINT PRIMARY KEY, token VARCHAR(64) UNIQUE);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save revisions of calendar events
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS calendarEvents (" +
+		"videoID VARCHAR(255) PRIMARY KEY, startTime BIGINT, sequence INT, modified BIGINT);")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save filter expressions
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS filterExprs (" +
+		"chatID BIGINT, channelID VARCHAR(255), expr TEXT, PRIMARY KEY (chatID, channelID));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save restricted videos opted out by chats
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS optOuts (" +
+		"chatID BIGINT, channelID VARCHAR(255), kind VARCHAR(32), PRIMARY KEY (chatID, channelID, kind));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save handles of subscribed channels
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS channelHandles (" +
+		"channelID VARCHAR(255) PRIMARY KEY, handle VARCHAR(255));")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create table to save chats which want guest appearance notices
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS guestChats (" +
+		"chatID BIGINT PRIMARY KEY);")
+	if err != nil {
+		return nil, err
+	}
+
 	return &database{DB: db}, nil
 }
 
@@ -156,9 +255,12 @@ func (db *database) getNoticesByVideoID(videoID string) ([]Notice, error) {
 		&results,
 		func(rows *sql.Rows, dest interface{}) error {
 			r := dest.(*Notice)
-			return rows.Scan(&r.videoID, &r.chatID, &r.messageID)
+			return rows.Scan(&r.videoID, &r.chatID, &r.messageID, &r.photo)
 		},
-		"SELECT videoID, chatID, messageID FROM notices WHERE videoID = ?;",
+		"SELECT notices.videoID, notices.chatID, notices.messageID, photoNotices.videoID IS NOT NULL "+
+			"FROM notices LEFT JOIN photoNotices "+
+			"ON notices.videoID = photoNotices.videoID AND notices.chatID = photoNotices.chatID "+
+			"WHERE notices.videoID = ?;",
 		videoID,
 	)
 
@@ -191,6 +293,239 @@ func (db *database) getChatsByChannelID(channelID string) ([]Chat, error) {
 	return results, nil
 }
 
+func (db *database) getChatTimezone(chatID int64) (string, error) {
+	var name string
+
+	err := db.QueryRow("SELECT name FROM timezones WHERE chatID = ?;", chatID).Scan(&name)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return name, nil
+}
+
+func (db *database) getChatLanguage(chatID int64) (string, error) {
+	var lang string
+
+	err := db.QueryRow("SELECT lang FROM languages WHERE chatID = ?;", chatID).Scan(&lang)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return lang, nil
+}
+
+func (db *database) getChatTemplate(chatID int64) (string, error) {
+	var content string
+
+	err := db.QueryRow("SELECT content FROM templates WHERE chatID = ?;", chatID).Scan(&content)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return content, nil
+}
+
+func (db *database) isPhotoChat(chatID int64) (bool, error) {
+	var exist bool
+
+	err := db.QueryRow("SELECT EXISTS(SELECT * FROM photoChats WHERE chatID = ?);", chatID).Scan(&exist)
+	if err != nil {
+		return false, err
+	}
+
+	return exist, nil
+}
+
+func (db *database) isGuestChat(chatID int64) (bool, error) {
+	var exist bool
+
+	err := db.QueryRow("SELECT EXISTS(SELECT * FROM guestChats WHERE chatID = ?);", chatID).Scan(&exist)
+	if err != nil {
+		return false, err
+	}
+
+	return exist, nil
+}
+
+// setChannelHandle saves channel handle, e.g. "@name", in lower case without "@".
+func (db *database) setChannelHandle(channelID, handle string) error {
+	handle = strings.ToLower(strings.TrimPrefix(handle, "@"))
+	if handle == "" {
+		return nil
+	}
+
+	_, err := db.Exec(
+		"INSERT INTO channelHandles (channelID, handle) VALUES (?, ?)"+
+			"ON DUPLICATE KEY UPDATE handle = VALUES(handle);",
+		channelID, handle,
+	)
+
+	return err
+}
+
+// getFilterLists returns blacklist & whitelist contents of channel in chat.
+func (db *database) getFilterLists(chatID int64, channelID string) (black, white string, err error) {
+	type rowFilter struct {
+		block   bool
+		content string
+	}
+
+	var filters []rowFilter
+
+	err = db.queryResults(
+		&filters,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*rowFilter)
+			return rows.Scan(&r.block, &r.content)
+		},
+		"SELECT block, content FROM filters WHERE chatID = ? AND channelID = ?;",
+		chatID, channelID,
+	)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, f := range filters {
+		if f.block {
+			black = f.content
+		} else {
+			white = f.content
+		}
+	}
+
+	return black, white, nil
+}
+
+func (db *database) getFilterExpr(chatID int64, channelID string) (string, error) {
+	var expr string
+
+	err := db.QueryRow(
+		"SELECT expr FROM filterExprs WHERE chatID = ? AND channelID = ?;",
+		chatID, channelID,
+	).Scan(&expr)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return expr, nil
+}
+
+// isOptedOut reports whether chat opted out kind of restricted videos
+// on the channel or chat-wide.
+func (db *database) isOptedOut(chatID int64, channelID, kind string) (bool, error) {
+	var exist bool
+
+	err := db.QueryRow(
+		"SELECT EXISTS(SELECT * FROM optOuts WHERE chatID = ? AND channelID IN (?, ?) AND kind = ?);",
+		chatID, channelID, globalFilterChannel, kind,
+	).Scan(&exist)
+	if err != nil {
+		return false, err
+	}
+
+	return exist, nil
+}
+
+// getOptOutKinds returns the kinds opted out by chat on exactly the channel.
+func (db *database) getOptOutKinds(chatID int64, channelID string) ([]string, error) {
+	var kinds []string
+
+	err := db.queryResults(
+		&kinds,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*string)
+			return rows.Scan(r)
+		},
+		"SELECT kind FROM optOuts WHERE chatID = ? AND channelID = ?;",
+		chatID, channelID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return kinds, nil
+}
+
+func (db *database) getCalendarToken(chatID int64) (string, error) {
+	var token string
+
+	err := db.QueryRow("SELECT token FROM calendars WHERE chatID = ?;", chatID).Scan(&token)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// getCalendarChatID returns sql.ErrNoRows if token not exists.
+func (db *database) getCalendarChatID(token string) (int64, error) {
+	var chatID int64
+
+	err := db.QueryRow("SELECT chatID FROM calendars WHERE token = ?;", token).Scan(&chatID)
+	if err != nil {
+		return 0, err
+	}
+
+	return chatID, nil
+}
+
+// reviseCalendarEvent records start time of event,
+// and increases its sequence if the video is rescheduled.
+func (db *database) reviseCalendarEvent(videoID string, startTime int64, now time.Time) error {
+	// Columns are updated from left to right, startTime must be the last one.
+	_, err := db.Exec(
+		"INSERT INTO calendarEvents (videoID, startTime, sequence, modified) VALUES (?, ?, 0, ?) "+
+			"ON DUPLICATE KEY UPDATE "+
+			"sequence = IF(startTime = VALUES(startTime), sequence, sequence + 1), "+
+			"modified = IF(startTime = VALUES(startTime), modified, VALUES(modified)), "+
+			"startTime = VALUES(startTime);",
+		videoID, startTime, now.Unix(),
+	)
+
+	return err
+}
+
+// getCalendarEvent returns current sequence & last modified time of event,
+// which are zero if it's never recorded.
+func (db *database) getCalendarEvent(videoID string) (int, time.Time, error) {
+	var sequence int
+	var modified int64
+
+	err := db.QueryRow(
+		"SELECT sequence, modified FROM calendarEvents WHERE videoID = ?;",
+		videoID,
+	).Scan(&sequence, &modified)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, nil
+	} else if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return sequence, time.Unix(modified, 0), nil
+}
+
+func (db *database) getViewerSamples(videoID string) ([]ViewerSample, error) {
+	var results []ViewerSample
+
+	err := db.queryResults(
+		&results,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*ViewerSample)
+			return rows.Scan(&r.Time, &r.Count)
+		},
+		"SELECT time, count FROM viewers WHERE videoID = ? ORDER BY time;",
+		videoID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (db *database) queryResults(
 	container interface{},
 	scan func(rows *sql.Rows, dest interface{}) error,
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+)
+
+// Number of recent videos which filters are tested against.
+const filterTestLength = 10
+
+// newFilterTestText evaluates current filters of chat against recent videos
+// of channel, or of all subscribed channels for chat-wide filters.
+func (s *Server) newFilterTestText(ctx context.Context, chatID int64, channelID string, lc locale) (string, error) {
+	heading, err := s.filterHeading(channelID, lc)
+	if err != nil {
+		return "", err
+	}
+
+	var videoIDs []string
+
+	scan := func(rows *sql.Rows, dest interface{}) error {
+		return rows.Scan(dest.(*string))
+	}
+
+	if channelID == globalFilterChannel {
+		err = s.db.queryResults(
+			&videoIDs, scan,
+			"SELECT videos.id FROM videos INNER JOIN subscribers ON videos.channelID = subscribers.channelID "+
+				"WHERE subscribers.chatID = ? ORDER BY videos.startTime DESC LIMIT ?;",
+			chatID, filterTestLength,
+		)
+	} else {
+		err = s.db.queryResults(
+			&videoIDs, scan,
+			"SELECT id FROM videos WHERE channelID = ? ORDER BY startTime DESC LIMIT ?;",
+			channelID, filterTestLength,
+		)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(videoIDs) == 0 {
+		return fmt.Sprintf(lc.text("filter.test_empty"), heading), nil
+	}
+
+	// Stored videos lack description, tags & duration, request them.
+	videos, err := s.yt.GetVideos(videoIDs, noticeVideoParts)
+	if err != nil {
+		return "", err
+	}
+
+	var list []string
+	var notified int
+
+	for _, v := range videos {
+		pass, err := s.applyFilters(ctx, chatID, v.Snippet.ChannelId, v)
+		if err != nil {
+			return "", err
+		}
+
+		mark := "🚫"
+		if pass {
+			mark = "✅"
+			notified++
+		}
+
+		list = append(list, fmt.Sprintf(
+			"%s %s",
+			mark,
+			tgbot.InlineLink(tgbot.EscapeText(v.Snippet.Title), ytVideoURLPrefix+v.Id),
+		))
+	}
+
+	return fmt.Sprintf(
+		"%s\n\n%s\n\n%s",
+		fmt.Sprintf(lc.text("filter.test_title"), len(videos), heading),
+		strings.Join(list, "\n"),
+		fmt.Sprintf(lc.text("filter.test_summary"), notified, len(videos)-notified),
+	), nil
+}
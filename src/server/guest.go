@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+)
+
+var (
+	// Handles mentioned in text, e.g. "@name" or "youtube.com/@name", but not emails.
+	handleMentionRe = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@([A-Za-z0-9_.\-]{3,30})`)
+	// Channel URLs mentioned in text.
+	channelURLRe = regexp.MustCompile(`youtube\.com/channel/(UC[A-Za-z0-9_\-]{22})`)
+)
+
+// refreshChannelHandles fetches handles of all subscribed channels from YouTube.
+func (s *Server) refreshChannelHandles() {
+	ctx := logging.WithCorrelationID(context.Background(), logging.NewID())
+	ctx = logging.NewContext(ctx, "task", "handles")
+	log := logging.FromContext(ctx)
+
+	channels, err := s.db.getChannels()
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return
+	}
+
+	var ids []string
+	for _, ch := range channels {
+		ids = append(ids, ch.id)
+	}
+
+	results, err := s.yt.GetChannels(ids, []string{"snippet"})
+	if err != nil {
+		log.Warning("YouTube API error", "error", err)
+		return
+	}
+
+	for _, c := range results {
+		if err := s.db.setChannelHandle(c.Id, c.Snippet.CustomUrl); err != nil {
+			log.Error("Database error", "error", err)
+		}
+	}
+}
+
+// featuredCacheTime is how long featured channels of a video are cached,
+// so newly subscribed channels are picked up & unused entries are dropped.
+const featuredCacheTime = time.Hour
+
+// featuredEntry caches featured channels of a video, until its text changes or it expires.
+type featuredEntry struct {
+	text     string
+	channels []Channel
+	expire   time.Time
+}
+
+// featuredChannels returns subscribed channels mentioned in video title or
+// description by handles or channel URLs, except the owner channel.
+// Results are cached per video, since notices are updated frequently
+// while title & description rarely change.
+func (s *Server) featuredChannels(video *ytapi.Video) ([]Channel, error) {
+	text := video.Snippet.Title + "\n" + video.Snippet.Description
+	now := s.clock.Now()
+
+	s.featuredMutex.Lock()
+	e, ok := s.featuredCache[video.Id]
+	s.featuredMutex.Unlock()
+
+	if ok && e.text == text && now.Before(e.expire) {
+		return e.channels, nil
+	}
+
+	channels, err := s.findFeaturedChannels(video, text)
+	if err != nil {
+		return nil, err
+	}
+
+	s.featuredMutex.Lock()
+	// Drop expired entries of videos which are deleted or never go live.
+	for id, e := range s.featuredCache {
+		if now.After(e.expire) {
+			delete(s.featuredCache, id)
+		}
+	}
+	s.featuredCache[video.Id] = featuredEntry{text: text, channels: channels, expire: now.Add(featuredCacheTime)}
+	s.featuredMutex.Unlock()
+
+	return channels, nil
+}
+
+// forgetFeaturedChannels removes cached featured channels of a completed or deleted video.
+func (s *Server) forgetFeaturedChannels(videoID string) {
+	s.featuredMutex.Lock()
+	defer s.featuredMutex.Unlock()
+
+	delete(s.featuredCache, videoID)
+}
+
+func (s *Server) findFeaturedChannels(video *ytapi.Video, text string) ([]Channel, error) {
+	mentions := make(map[string]bool)
+	for _, m := range handleMentionRe.FindAllStringSubmatch(text, -1) {
+		// Handles can't end with dots, it's the end of a sentence.
+		mentions["@"+strings.ToLower(strings.TrimRight(m[1], "."))] = true
+	}
+	for _, m := range channelURLRe.FindAllStringSubmatch(text, -1) {
+		mentions[m[1]] = true
+	}
+
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	type rowChannel struct {
+		Channel
+		handle sql.NullString
+	}
+
+	var rows []rowChannel
+
+	err := s.db.queryResults(
+		&rows,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*rowChannel)
+			return rows.Scan(&r.id, &r.title, &r.handle)
+		},
+		"SELECT channels.id, channels.title, channelHandles.handle FROM "+
+			"channels LEFT JOIN channelHandles ON channels.id = channelHandles.channelID;",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Channel
+	for _, r := range rows {
+		if r.id == video.Snippet.ChannelId {
+			continue
+		}
+
+		if mentions[r.id] || (r.handle.Valid && mentions["@"+r.handle.String]) {
+			results = append(results, r.Channel)
+		}
+	}
+
+	return results, nil
+}
+
+// guestChats returns chats which want guest appearance notices of video,
+// mapped to featured channels they subscribed.
+// Subscribers of the owner channel are excluded, they're notified anyway.
+func (s *Server) guestChats(video *ytapi.Video) (map[int64][]Channel, error) {
+	channels, err := s.featuredChannels(video)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int64][]Channel)
+
+	for _, ch := range channels {
+		var chatIDs []int64
+
+		err := s.db.queryResults(
+			&chatIDs,
+			func(rows *sql.Rows, dest interface{}) error {
+				r := dest.(*int64)
+				return rows.Scan(r)
+			},
+			"SELECT subscribers.chatID FROM "+
+				"subscribers INNER JOIN guestChats ON subscribers.chatID = guestChats.chatID "+
+				"WHERE subscribers.channelID = ? AND subscribers.chatID NOT IN "+
+				"(SELECT chatID FROM subscribers WHERE channelID = ?);",
+			ch.id, video.Snippet.ChannelId,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range chatIDs {
+			results[id] = append(results[id], ch)
+		}
+	}
+
+	return results, nil
+}
+
+// newFeaturingText returns label of featured guest channels.
+func newFeaturingText(channels []Channel, lc locale) string {
+	var escaped []string
+	for _, ch := range channels {
+		escaped = append(escaped, tgbot.BordText(tgbot.EscapeText(ch.title)))
+	}
+
+	return fmt.Sprintf(lc.text("guest.featuring"), strings.Join(escaped, ", "))
+}
+
+// guestHandler handles guest appearance notices setting request.
+func (s *Server) guestHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	usage := tgbot.InlineCode(tgbot.EscapeText("/guest <on|off>"))
+
+	// Show current setting.
+	if len(elements) == 1 {
+		guest, err := s.db.isGuestChat(chatID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+			return
+		}
+
+		state := lc.text("guest.off")
+		if guest {
+			state = lc.text("guest.on")
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("guest.current"), state, usage))
+		return
+	}
+
+	var err error
+	switch strings.ToLower(elements[1]) {
+	case "on":
+		_, err = s.db.Exec("INSERT IGNORE INTO guestChats (chatID) VALUES (?);", chatID)
+	case "off":
+		_, err = s.db.Exec("DELETE FROM guestChats WHERE chatID = ?;", chatID)
+	default:
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("guest.usage"), usage))
+		return
+	}
+
+	if err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("guest.set_fail"))
+		return
+	}
+
+	if strings.ToLower(elements[1]) == "on" {
+		msgConfig = tgbot.NewMessage(chatID, lc.text("guest.enabled"))
+	} else {
+		msgConfig = tgbot.NewMessage(chatID, lc.text("guest.disabled"))
+	}
+}
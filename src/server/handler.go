@@ -1,36 +1,43 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/filter"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/hub"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
-	"github.com/golang/glog"
 )
 
-func (s *Server) noticeHandler(feed hub.Feed) {
+func (s *Server) noticeHandler(ctx context.Context, feed hub.Feed) {
+	log := logging.FromContext(ctx)
+
 	if feed.Entry != nil {
+		log = log.With("videoID", feed.Entry.VideoID)
+		ctx = logging.NewContext(ctx, "videoID", feed.Entry.VideoID)
+		log.Info("Handle feed entry", "channelID", feed.Entry.ChannelID)
+
 		// If it's a normal entry
 		// Check if it's already exists.
 		var exists bool
 		err := s.db.QueryRow("SELECT EXISTS(SELECT * FROM videos WHERE id = ?);", feed.Entry.VideoID).Scan(&exists)
 		if err != nil && err != sql.ErrNoRows {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			return
 		} else if exists {
 			// If the video already exists, then check if it's completed.
 			var completed bool
 			err := s.db.QueryRow("SELECT completed FROM videos WHERE id = ?;", feed.Entry.VideoID).Scan(&completed)
 			if err != nil && err != sql.ErrNoRows {
-				glog.Error(err)
+				log.Error("Database error", "error", err)
 				return
 			} else if completed {
 				// If the video already completed, then discard.
@@ -41,10 +48,10 @@ func (s *Server) noticeHandler(feed hub.Feed) {
 		// Request corresponding video resource
 		v, err := s.yt.GetVideo(
 			feed.Entry.VideoID,
-			[]string{"snippet", "liveStreamingDetails"},
+			noticeVideoParts,
 		)
 		if err != nil {
-			glog.Warning(err)
+			log.Warning("YouTube API error", "error", err)
 			return
 		} else if !ytapi.IsLiveBroadcast(v) {
 			// If the video is not a live broadcast, then discard.
@@ -55,7 +62,7 @@ func (s *Server) noticeHandler(feed hub.Feed) {
 				v.Id, true,
 			)
 			if err != nil {
-				glog.Error(err)
+				log.Error("Database error", "error", err)
 			}
 			return
 		}
@@ -69,27 +76,35 @@ func (s *Server) noticeHandler(feed hub.Feed) {
 			v.Id, v.Snippet.Title, v.Snippet.ChannelId, v.Snippet.ChannelTitle, t.Unix(), false,
 		)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			return
 		}
 
-		s.sendNotices(v)
-		s.setupVideoAutoRecorder(v)
-		s.tryDiligentScheduler(v)
+		s.sendNotices(ctx, v)
+		if !feed.Received.IsZero() {
+			fanoutLatency.Since(feed.Received)
+			log.Info("Notices sent", "latency", time.Since(feed.Received).Seconds())
+		}
+
+		s.setupVideoAutoRecorder(ctx, v)
+		s.tryDiligentScheduler(ctx, v)
+		s.tryLiveTracker(ctx, v)
 
 		// Update channel title
 		_, err = s.db.Exec("UPDATE channels SET title = ? WHERE id = ?;", v.Snippet.ChannelTitle, v.Snippet.ChannelId)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 		}
 	} else if feed.DeletedEntry != nil {
 		// Get video id
 		videoID := strings.Split(feed.DeletedEntry.Ref, ":")[2]
+		log = log.With("videoID", videoID)
+		log.Info("Handle deleted entry")
 
 		// Query notice rows according to video id.
 		notices, err := s.db.getNoticesByVideoID(videoID)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			return
 		}
 
@@ -101,9 +116,9 @@ func (s *Server) noticeHandler(feed hub.Feed) {
 				if err != nil {
 					switch err.(type) {
 					case tgbot.Error:
-						glog.Error(err)
+						log.Error("Failed to delete notice", "error", err, "chatID", n.chatID)
 					default:
-						glog.Warning(err)
+						log.Warning("Failed to delete notice", "error", err, "chatID", n.chatID)
 					}
 				}
 			}
@@ -111,19 +126,31 @@ func (s *Server) noticeHandler(feed hub.Feed) {
 
 		// Remove deleted video from notices table.
 		if _, err := s.db.Exec("DELETE FROM notices WHERE videoID = ?;", videoID); err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
+		}
+
+		if _, err := s.db.Exec("DELETE FROM photoNotices WHERE videoID = ?;", videoID); err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		if _, err := s.db.Exec("DELETE FROM calendarEvents WHERE videoID = ?;", videoID); err != nil {
+			log.Error("Database error", "error", err)
 		}
 
+		s.forgetFeaturedChannels(videoID)
+
 		// Remove deleted video from records table.
 		if _, err := s.db.Exec("DELETE FROM records WHERE videoID = ?;", videoID); err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 		}
 	} else {
-		glog.Warning(errors.New("receive a empty feed"))
+		log.Warning("Receive a empty feed")
 	}
 }
 
-func (s *Server) setupVideoAutoRecorder(video *ytapi.Video) {
+func (s *Server) setupVideoAutoRecorder(ctx context.Context, video *ytapi.Video) {
+	log := logging.FromContext(ctx)
+
 	// Query autorecorders from db according to channel id.
 	var chatIDs []int64
 
@@ -138,15 +165,15 @@ func (s *Server) setupVideoAutoRecorder(video *ytapi.Video) {
 	)
 
 	if err != nil {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		return
 	}
 
 	// Insert or ignore new rows to records table.
 	for _, cid := range chatIDs {
-		b, err := s.applyFilters(cid, video)
+		b, err := s.applyFilters(ctx, cid, video.Snippet.ChannelId, video)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			continue
 		} else if !b { // No pass
 			continue
@@ -156,124 +183,175 @@ func (s *Server) setupVideoAutoRecorder(video *ytapi.Video) {
 			"INSERT IGNORE INTO records (chatID, videoID) VALUES (?, ?);",
 			cid, video.Id,
 		); err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			continue
 		}
 	}
 }
 
-func (s *Server) applyFilters(chatID int64, video *ytapi.Video) (bool, error) {
-	type rowFilter struct {
-		block   bool
-		content string
+// globalFilterChannel is the channel ID of chat-wide filters.
+const globalFilterChannel = "*"
+
+// applyFilters reports whether video should be notified to chat by filters
+// of channelID, the subscribed channel, which is the owner or a featured guest.
+//
+// Restricted videos are skipped if chat opted out them on the channel or chat-wide.
+// Chat-wide filters are combined with channel filters:
+// blacklists of both are applied, while channel whitelist & expression
+// override chat-wide ones if set.
+func (s *Server) applyFilters(ctx context.Context, chatID int64, channelID string, video *ytapi.Video) (bool, error) {
+	log := logging.FromContext(ctx)
+
+	// Skip restricted videos which chat opted out.
+	for _, kind := range restrictionKinds(video) {
+		out, err := s.db.isOptedOut(chatID, channelID, kind)
+		if err != nil {
+			return false, err
+		} else if out {
+			log.Info("Apply opt-out", "chatID", chatID, "kind", kind)
+			return false, nil
+		}
 	}
 
-	var err error
-	var filters []rowFilter
-
-	err = s.db.queryResults(
-		&filters,
-		func(rows *sql.Rows, dest interface{}) error {
-			filter := dest.(*rowFilter)
-			return rows.Scan(&filter.block, &filter.content)
-		},
-		"SELECT block, content FROM filters WHERE chatID = ? AND channelID = ?;",
-		chatID, video.Snippet.ChannelId,
-	)
+	black, white, err := s.db.getFilterLists(chatID, channelID)
+	if err != nil {
+		return false, err
+	}
 
+	globalBlack, globalWhite, err := s.db.getFilterLists(chatID, globalFilterChannel)
 	if err != nil {
 		return false, err
 	}
 
+	if white == "" {
+		white = globalWhite
+	}
+
 	title := strings.ToLower(video.Snippet.Title)
 
-	for _, f := range filters {
-		if f.content != "" {
-			words := strings.Split(f.content, ",")
+	for _, content := range []string{black, globalBlack} {
+		if content != "" && containsAny(title, strings.Split(content, ",")) {
+			log.Info("Apply filter", "chatID", chatID, "block", true, "content", content)
+			return false, nil
+		}
+	}
 
-			if f.block == containsAny(title, words) {
-				glog.Infof("Apply filter {chatID: %v\tblock: %v\tcontent:%v}", chatID, f.block, f.content)
-				return false, nil
-			}
+	if white != "" && !containsAny(title, strings.Split(white, ",")) {
+		log.Info("Apply filter", "chatID", chatID, "block", false, "content", white)
+		return false, nil
+	}
+
+	content, err := s.db.getFilterExpr(chatID, channelID)
+	if err != nil {
+		return false, err
+	} else if content == "" {
+		if content, err = s.db.getFilterExpr(chatID, globalFilterChannel); err != nil {
+			return false, err
+		}
+	}
+
+	if content != "" {
+		expr, err := filter.Parse(content)
+		if err != nil {
+			// Stored expressions are validated, ignore it if syntax changed.
+			log.Warning("Ignore invalid filter expression", "chatID", chatID, "error", err)
+		} else if !expr.Match(newFilterVideo(video)) {
+			log.Info("Apply filter", "chatID", chatID, "expr", content)
+			return false, nil
 		}
 	}
 
 	return true, nil
 }
 
-const ytVideoURLPrefix = "https://www.youtube.com/watch?v="
+func newFilterVideo(video *ytapi.Video) filter.Video {
+	return filter.Video{
+		Title:       video.Snippet.Title,
+		Description: video.Snippet.Description,
+		Tags:        video.Snippet.Tags,
+		Duration:    ytapi.VideoDuration(video),
+		MembersOnly: ytapi.IsMembersOnly(video),
+		Unfinished:  ytapi.IsUpcomingLiveBroadcast(video) || ytapi.IsLiveLiveBroadcast(video),
+	}
+}
+
+// Kinds of restricted videos which chats can opt out.
+const (
+	membersOptOut = "members"
+	ageOptOut     = "age"
+	regionOptOut  = "region"
+)
+
+var optOutKinds = []string{membersOptOut, ageOptOut, regionOptOut}
+
+// restrictionKinds returns opt-out kinds of restrictions applied on video.
+func restrictionKinds(video *ytapi.Video) []string {
+	var kinds []string
+
+	if ytapi.IsMembersOnly(video) {
+		kinds = append(kinds, membersOptOut)
+	}
+	if ytapi.IsAgeRestricted(video) {
+		kinds = append(kinds, ageOptOut)
+	}
+	if ytapi.IsRegionRestricted(video) {
+		kinds = append(kinds, regionOptOut)
+	}
+
+	return kinds
+}
+
+const (
+	ytVideoURLPrefix   = "https://www.youtube.com/watch?v="
+	ytChannelURLPrefix = "https://www.youtube.com/channel/"
+)
+
+// newNotifyMessageText renders notification message text with default layout.
+func newNotifyMessageText(video *ytapi.Video, lc locale) string {
+	f := newNoticeFields(video, lc)
 
-func newNotifyMessageText(video *ytapi.Video) string {
 	// Create basic info (title, link, channel).
 	basic := fmt.Sprintf(
 		"%s\n%s",
 		tgbot.InlineLink(
-			tgbot.BordText(tgbot.EscapeText(video.Snippet.Title)),
-			ytVideoURLPrefix+video.Id,
+			tgbot.BordText(tgbot.EscapeText(f.Title)),
+			f.URL,
 		),
-		tgbot.ItalicText(tgbot.EscapeText(video.Snippet.ChannelTitle)),
+		tgbot.ItalicText(tgbot.EscapeText(f.Channel)),
 	)
 
-	liveStreamingDetails := video.LiveStreamingDetails
-
-	if liveStreamingDetails == nil {
-		return basic
+	// Tag restricted video.
+	if f.Restrictions != "" {
+		basic = fmt.Sprintf("%s\n⚠️ %s", basic, tgbot.EscapeText(f.Restrictions))
 	}
 
-	scheduledStartTime := liveStreamingDetails.ScheduledStartTime
-	actualStartTime := liveStreamingDetails.ActualStartTime
-	actualEndTime := liveStreamingDetails.ActualEndTime
-
-	var t time.Time
-	var liveStatus string
-	var timeTitle, timeDetail string
-	var appendix string
-
-	if actualEndTime != "" {
-		// It's a completed live.
-		liveStatus = "Completed"
-
-		timeTitle = "Actual End Time"
-		t, _ = time.Parse(time.RFC3339, actualEndTime)
-		timeDetail = t.Local().Format("2006/01/02 15:04:05")
-
-		start, _ := time.Parse(time.RFC3339, actualStartTime)
-		dur := t.Sub(start).Round(time.Second)
-		appendix = fmt.Sprintf(
-			"%02d:%02d:%02d",
-			int(dur.Hours()),
-			int(dur.Minutes())%60,
-			int(dur.Seconds())%60,
-		)
-	} else if actualStartTime != "" {
-		// It's a live live.
-		liveStatus = "Live"
-
-		timeTitle = "Actual Start Time"
-		t, _ = time.Parse(time.RFC3339, actualStartTime)
-		timeDetail = t.Local().Format("2006/01/02 15:04:05")
-	} else if scheduledStartTime != "" {
-		// It's a upcoming live.
-		liveStatus = "Upcoming"
-
-		timeTitle = "Scheduled Start Time"
-		t, _ = time.Parse(time.RFC3339, scheduledStartTime)
-		timeDetail = t.Local().Format("2006/01/02 15:04:05")
+	if video.LiveStreamingDetails == nil {
+		return basic
 	}
 
 	detail := fmt.Sprintf(
 		"%s\n%s\n\n%s\n%s",
-		tgbot.BordText("Status"),
-		tgbot.ItalicText(liveStatus),
-		tgbot.BordText(timeTitle),
-		tgbot.ItalicText(timeDetail),
+		tgbot.BordText(lc.text("notice.status")),
+		tgbot.ItalicText(tgbot.EscapeText(f.Status)),
+		tgbot.BordText(tgbot.EscapeText(f.TimeTitle)),
+		tgbot.ItalicText(tgbot.EscapeText(f.Time)),
 	)
 
+	var appendixTitle, appendix string
+
+	if f.Duration != "" {
+		appendixTitle = lc.text("notice.duration")
+		appendix = f.Duration
+	} else if f.Viewers != 0 {
+		appendixTitle = lc.text("notice.viewers")
+		appendix = fmt.Sprint(f.Viewers)
+	}
+
 	if appendix != "" {
 		detail = fmt.Sprintf(
 			"%s\n\n%s\n%s",
 			detail,
-			tgbot.BordText("Duration"),
+			tgbot.BordText(appendixTitle),
 			tgbot.ItalicText(appendix),
 		)
 	}
@@ -293,17 +371,31 @@ func (s *Server) recorderHandler(w http.ResponseWriter, r *http.Request) {
 
 	_ = json.Unmarshal(body, &data)
 
+	// Continue the trace of request if recorder echoes its correlation ID.
+	id := r.Header.Get("X-Correlation-ID")
+	if id == "" {
+		id = logging.NewID()
+	}
+
+	ctx := logging.WithCorrelationID(r.Context(), id)
+	ctx = logging.NewContext(ctx, "action", data.Action)
+	log := logging.FromContext(ctx)
+
+	log.Info("Recorder callback received")
+
 	switch data.Action {
 	case "record":
-		s.recorderRecordHandler(w, r, body)
+		s.recorderRecordHandler(ctx, w, body)
 	case "download":
-		s.recorderDownloadHandler(w, r, body)
+		s.recorderDownloadHandler(ctx, w, body)
 	default:
-		glog.Error("Invalid action type:", data.Action)
+		log.Error("Invalid action type")
 	}
 }
 
-func (s *Server) recorderRecordHandler(w http.ResponseWriter, r *http.Request, body []byte) {
+func (s *Server) recorderRecordHandler(ctx context.Context, w http.ResponseWriter, body []byte) {
+	log := logging.FromContext(ctx)
+
 	var data struct {
 		Success  bool   `json:"success"`
 		ChatID   int64  `json:"chatID"`
@@ -313,24 +405,26 @@ func (s *Server) recorderRecordHandler(w http.ResponseWriter, r *http.Request, b
 
 	_ = json.Unmarshal(body, &data)
 
+	lc := s.chatLocale(ctx, data.ChatID)
+
 	v, err := s.yt.GetVideo(
 		data.VideoID,
-		[]string{"snippet", "liveStreamingDetails"},
+		noticeVideoParts,
 	)
 	if err != nil {
-		glog.Warning(err)
+		log.Warning("YouTube API error", "error", err)
 		return
 	} else if !data.Success {
 		msgConfig := tgbot.NewMessage(
 			data.ChatID,
 			fmt.Sprintf(
-				"Failed to record %s, check your recorder",
+				lc.text("record.failed"),
 				tgbot.InlineLink(tgbot.EscapeText(v.Snippet.Title), ytVideoURLPrefix+v.Id),
 			),
 		)
 		msgConfig.DisableNotification = true
 
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 		return
 	} else if data.Success && ytapi.IsLiveBroadcast(v) && !ytapi.IsCompletedLiveBroadcast(v) {
 		w.Header().Set("Content-Type", "application/json")
@@ -348,7 +442,7 @@ func (s *Server) recorderRecordHandler(w http.ResponseWriter, r *http.Request, b
 	msgConfig := tgbot.NewMessage(
 		data.ChatID,
 		fmt.Sprintf(
-			"%s recorded as\n%s",
+			lc.text("record.recorded"),
 			tgbot.InlineLink(tgbot.EscapeText(v.Snippet.Title), ytVideoURLPrefix+v.Id),
 			tgbot.InlineCode(tgbot.EscapeText(data.Filename)),
 		),
@@ -356,19 +450,19 @@ func (s *Server) recorderRecordHandler(w http.ResponseWriter, r *http.Request, b
 	msgConfig.DisableNotification = true
 	msgConfig.DisableWebPagePreview = true
 
-	s.tgSend(msgConfig)
+	s.tgSend(ctx, msgConfig)
 
 	// Remove record from table
 	if _, err = s.db.Exec(
 		"DELETE FROM records WHERE chatID = ? AND videoID = ?;",
 		data.ChatID, data.VideoID,
 	); err != nil {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		return
 	}
 }
 
-func (s *Server) recorderDownloadHandler(w http.ResponseWriter, r *http.Request, body []byte) {
+func (s *Server) recorderDownloadHandler(ctx context.Context, w http.ResponseWriter, body []byte) {
 	var data struct {
 		Success     bool   `json:"success"`
 		Description string `json:"description"`
@@ -387,17 +481,19 @@ func (s *Server) recorderDownloadHandler(w http.ResponseWriter, r *http.Request,
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
 
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 		return
 	}
 
+	lc := s.chatLocale(ctx, data.ChatID)
+
 	extRemoved := data.Filename[:strings.LastIndex(data.Filename, ".")]
 	title := extRemoved[:strings.LastIndex(extRemoved, ".")]
 
 	msgConfig := tgbot.NewMessage(
 		data.ChatID,
 		fmt.Sprintf(
-			"%s downloaded as\n%s",
+			lc.text("download.done"),
 			tgbot.InlineLink(tgbot.EscapeText(title), ytVideoURLPrefix+data.VideoID),
 			tgbot.InlineCode(tgbot.EscapeText(data.Filename)),
 		),
@@ -405,5 +501,5 @@ func (s *Server) recorderDownloadHandler(w http.ResponseWriter, r *http.Request,
 	msgConfig.DisableNotification = true
 	msgConfig.DisableWebPagePreview = true
 
-	s.tgSend(msgConfig)
+	s.tgSend(ctx, msgConfig)
 }
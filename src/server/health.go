@@ -0,0 +1,270 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/recorder"
+)
+
+const (
+	healthTimeout = 5 * time.Second
+	// External checks are cached, so frequent probes won't exhaust
+	// YouTube quota or hit Telegram rate limit.
+	healthCacheTime = time.Minute
+)
+
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthFail     = "fail"
+)
+
+// healthReport is the response of health endpoints.
+type healthReport struct {
+	// Status is fail if any critical check fails,
+	// or degraded if any non-critical check fails.
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks"`
+}
+
+// healthCheck is the result of checking a dependency.
+type healthCheck struct {
+	Status    string      `json:"status"`
+	Error     string      `json:"error,omitempty"`
+	Latency   float64     `json:"latency_seconds"`
+	CheckedAt time.Time   `json:"checked_at"`
+	Detail    interface{} `json:"detail,omitempty"`
+}
+
+// healthProbe checks a dependency, and returns details of it.
+type healthProbe struct {
+	name     string
+	critical bool
+	cached   bool
+	probe    func() (interface{}, error)
+}
+
+// healthzHandler serves liveness, which only checks database.
+func (s *Server) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	s.writeHealthReport(w, s.runHealthProbes([]healthProbe{
+		{name: "database", critical: true, probe: s.probeDatabase},
+	}))
+}
+
+// readyzHandler serves readiness, which checks every dependency.
+// Recorders are owned by chats, and hub leases are kept in memory and
+// renewed on their own, so both only degrade readiness.
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	s.writeHealthReport(w, s.runHealthProbes([]healthProbe{
+		{name: "database", critical: true, probe: s.probeDatabase},
+		{name: "telegram", critical: true, cached: true, probe: s.probeTelegram},
+		{name: "youtube", critical: true, cached: true, probe: s.probeYouTube},
+		{name: "hub", probe: s.probeHub},
+		{name: "recorders", cached: true, probe: s.probeRecorders},
+	}))
+}
+
+func (s *Server) writeHealthReport(w http.ResponseWriter, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if report.Status == healthFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// runHealthProbes runs probes concurrently.
+func (s *Server) runHealthProbes(probes []healthProbe) healthReport {
+	report := healthReport{Status: healthOK, Checks: make(map[string]healthCheck)}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, p := range probes {
+		wg.Add(1)
+
+		go func(p healthProbe) {
+			defer wg.Done()
+
+			c := s.runHealthProbe(p)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[p.name] = c
+			if c.Status != healthOK {
+				if p.critical {
+					report.Status = healthFail
+				} else if report.Status == healthOK {
+					report.Status = healthDegraded
+				}
+			}
+		}(p)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// runHealthProbe runs probe within timeout, or returns its cached result.
+func (s *Server) runHealthProbe(p healthProbe) healthCheck {
+	if p.cached {
+		s.healthMutex.Lock()
+		c, ok := s.healthCache[p.name]
+		s.healthMutex.Unlock()
+
+		if ok && time.Since(c.CheckedAt) < healthCacheTime {
+			return c
+		}
+	}
+
+	type result struct {
+		detail interface{}
+		err    error
+	}
+
+	start := time.Now()
+	ch := make(chan result, 1)
+
+	go func() {
+		detail, err := p.probe()
+		ch <- result{detail, err}
+	}()
+
+	var r result
+	select {
+	case r = <-ch:
+	case <-time.After(healthTimeout):
+		r.err = fmt.Errorf("timeout after %s", healthTimeout)
+	}
+
+	c := healthCheck{
+		Status:    healthOK,
+		Latency:   time.Since(start).Seconds(),
+		CheckedAt: start,
+		Detail:    r.detail,
+	}
+
+	if r.err != nil {
+		logging.With("check", p.name).Warning("Health check failed", "error", r.err)
+		c.Status = healthFail
+		c.Error = r.err.Error()
+	}
+
+	if p.cached {
+		s.healthMutex.Lock()
+		s.healthCache[p.name] = c
+		s.healthMutex.Unlock()
+	}
+
+	return c
+}
+
+func (s *Server) probeDatabase() (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+
+	return nil, s.db.PingContext(ctx)
+}
+
+func (s *Server) probeTelegram() (interface{}, error) {
+	user, err := s.tg.GetMe()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"username": user.UserName}, nil
+}
+
+func (s *Server) probeYouTube() (interface{}, error) {
+	return nil, s.yt.Ping()
+}
+
+// probeHub checks every subscribed channel has a lease not expired yet.
+// Subscriptions still waiting for verification are pending, which is fine.
+func (s *Server) probeHub() (interface{}, error) {
+	channels, err := s.db.getChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	leases := s.hub.Leases()
+	now := time.Now()
+
+	var detail struct {
+		Subscribed int `json:"subscribed"`
+		Verified   int `json:"verified"`
+		Pending    int `json:"pending"`
+		Expired    int `json:"expired"`
+	}
+
+	detail.Subscribed = len(channels)
+	for _, ch := range channels {
+		if l, ok := leases[ch.id]; !ok {
+			detail.Pending++
+		} else if !l.Expires.IsZero() && l.Expires.Before(now) {
+			detail.Expired++
+		} else {
+			detail.Verified++
+		}
+	}
+
+	if detail.Expired != 0 {
+		return detail, fmt.Errorf("%d of %d leases expired", detail.Expired, detail.Subscribed)
+	}
+
+	return detail, nil
+}
+
+// probeRecorders pings every distinct recorder.
+func (s *Server) probeRecorders() (interface{}, error) {
+	recorders := make(map[string]recorder.Recorder)
+	for _, r := range s.recorderTable {
+		recorders[r.Url] = r
+	}
+
+	var detail struct {
+		Reachable   int `json:"reachable"`
+		Unreachable int `json:"unreachable"`
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, r := range recorders {
+		wg.Add(1)
+
+		go func(r recorder.Recorder) {
+			defer wg.Done()
+
+			err := r.Ping()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				detail.Unreachable++
+			} else {
+				detail.Reachable++
+			}
+		}(r)
+	}
+
+	wg.Wait()
+
+	if detail.Unreachable != 0 {
+		return detail, errors.New("some recorders are unreachable")
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/i18n"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+)
+
+const (
+	// Number of results per inline query page, Telegram allows 50 at most.
+	inlinePageSize = 20
+	// How long inline query results are cached, by server & Telegram.
+	inlineCacheTime = 60 * time.Second
+	// Inline query will not list upcoming lives which are overdue too long.
+	inlineOverdueLimit = 24 * time.Hour
+)
+
+type inlineVideo struct {
+	id, title    string
+	channelTitle string
+	startTime    int64
+}
+
+type inlineCacheEntry struct {
+	videos []inlineVideo
+	expire time.Time
+}
+
+// inlineQueryHandler lists upcoming & live streams matching query text.
+func (s *Server) inlineQueryHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	query := update.InlineQuery
+
+	// Private chat ID equals to user ID.
+	lc := s.chatLocale(ctx, int64(query.From.ID))
+	if lang, err := s.db.getChatLanguage(int64(query.From.ID)); err == nil && lang == "" {
+		if lang = i18n.Match(query.From.LanguageCode); lang != "" {
+			lc.lang = lang
+		}
+	}
+
+	offset, _ := strconv.Atoi(query.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	videos, err := s.searchInlineVideos(strings.TrimSpace(query.Query))
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return
+	}
+
+	var results []interface{}
+	nextOffset := ""
+
+	if offset < len(videos) {
+		end := offset + inlinePageSize
+		if end < len(videos) {
+			nextOffset = strconv.Itoa(end)
+		} else {
+			end = len(videos)
+		}
+
+		page := videos[offset:end]
+		live := s.liveInlineVideos(page)
+
+		for _, v := range page {
+			results = append(results, newInlineVideoArticle(v, live[v.id], lc))
+		}
+	}
+
+	inlineConfig := tgbot.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     int(inlineCacheTime.Seconds()),
+		// Results are rendered in the timezone & language of user.
+		IsPersonal: true,
+		NextOffset: nextOffset,
+	}
+
+	if results == nil {
+		inlineConfig.Results = []interface{}{}
+	}
+
+	if _, err := s.tg.AnswerInlineQuery(inlineConfig); err != nil {
+		switch err.(type) {
+		case tgbot.Error:
+			log.Error("Telegram rejected request", "error", err)
+		default:
+			log.Warning("Telegram request failed", "error", err)
+		}
+	}
+}
+
+// searchInlineVideos queries upcoming & live videos whose title or channel
+// matches keyword, results are cached for a while.
+func (s *Server) searchInlineVideos(keyword string) ([]inlineVideo, error) {
+	key := strings.ToLower(keyword)
+	now := s.clock.Now()
+
+	s.inlineMutex.Lock()
+	entry, ok := s.inlineCache[key]
+	s.inlineMutex.Unlock()
+
+	if ok && now.Before(entry.expire) {
+		return entry.videos, nil
+	}
+
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword) + "%"
+
+	var videos []inlineVideo
+
+	err := s.db.queryResults(
+		&videos,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*inlineVideo)
+			return rows.Scan(&r.id, &r.title, &r.channelTitle, &r.startTime)
+		},
+		"SELECT id, title, channelTitle, startTime FROM videos "+
+			"WHERE NOT completed AND startTime > ? "+
+			"AND (title LIKE ? OR channelTitle LIKE ? OR channelID = ?) "+
+			"ORDER BY startTime;",
+		now.Add(-inlineOverdueLimit).Unix(), pattern, pattern, keyword,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.inlineMutex.Lock()
+	// Drop expired entries to keep cache small.
+	for k, e := range s.inlineCache {
+		if now.After(e.expire) {
+			delete(s.inlineCache, k)
+		}
+	}
+	s.inlineCache[key] = inlineCacheEntry{videos: videos, expire: now.Add(inlineCacheTime)}
+	s.inlineMutex.Unlock()
+
+	return videos, nil
+}
+
+// liveInlineVideos reports which videos are live now, since lives may start
+// early or late. Live trackers run exactly while videos are live, so it costs
+// no YouTube API quota on every keystroke.
+func (s *Server) liveInlineVideos(videos []inlineVideo) map[string]bool {
+	s.trackerMutex.Lock()
+	defer s.trackerMutex.Unlock()
+
+	live := make(map[string]bool)
+	for _, v := range videos {
+		live[v.id] = s.trackerTable[v.id]
+	}
+
+	return live
+}
+
+func newInlineVideoArticle(v inlineVideo, live bool, lc locale) tgbot.InlineQueryResultArticle {
+	t := time.Unix(v.startTime, 0)
+
+	status := lc.raw("notice.upcoming")
+	if live {
+		status = lc.raw("notice.live")
+	}
+
+	text := fmt.Sprintf(
+		"%s\n%s\n\n%s\n%s",
+		tgbot.InlineLink(tgbot.BordText(tgbot.EscapeText(v.title)), ytVideoURLPrefix+v.id),
+		tgbot.ItalicText(tgbot.EscapeText(v.channelTitle)),
+		tgbot.BordText(tgbot.EscapeText(status)),
+		tgbot.ItalicText(tgbot.EscapeText(lc.timeWithRelative(t))),
+	)
+
+	article := tgbot.NewInlineQueryResultArticle(v.id, v.title, text)
+	article.URL = ytVideoURLPrefix + v.id
+	article.Description = fmt.Sprintf("%s\n%s · %s", v.channelTitle, status, lc.timeWithRelative(t))
+	article.ThumbURL = fmt.Sprintf("https://i.ytimg.com/vi/%s/mqdefault.jpg", v.id)
+
+	return article
+}
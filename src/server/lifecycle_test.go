@@ -0,0 +1,28 @@
+package server
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var requireDatabase = flag.Bool("require-database", false, "Fail instead of skipping tests if TEST_DATABASE_DSN is not set")
+
+func TestLifecycle(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" && *requireDatabase {
+		t.Fatal("TEST_DATABASE_DSN is not set")
+	} else if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set, run `make test-lifecycle` for a scratch database")
+	}
+
+	sim, err := NewSimulation(dsn)
+	if err != nil {
+		t.Fatalf("NewSimulation() error = %v", err)
+	}
+	defer sim.Close()
+
+	if err := sim.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata" // embedded timezone database
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/i18n"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+)
+
+// locale is the rendering preferences of a chat.
+type locale struct {
+	loc  *time.Location
+	lang string
+
+	// now is the time of rendering, read from server clock.
+	now time.Time
+}
+
+// text returns the escaped message format of key, ready to be formatted with MarkdownV2 arguments.
+func (lc locale) text(key string) string {
+	return tgbot.EscapeText(i18n.Lookup(lc.lang, key))
+}
+
+// raw returns the plain message format of key, for texts without markup like buttons.
+func (lc locale) raw(key string) string {
+	return i18n.Lookup(lc.lang, key)
+}
+
+// relative formats t as plain relative time from now, e.g. "in 2h 15m".
+func (lc locale) relative(t time.Time) string {
+	dur := t.Sub(lc.now)
+	short := formatDurationShort(dur)
+
+	if short == "" {
+		return lc.raw("relative.now")
+	} else if dur > 0 {
+		return fmt.Sprintf(lc.raw("relative.future"), short)
+	}
+
+	return fmt.Sprintf(lc.raw("relative.past"), short)
+}
+
+// timeWithRelative formats t as plain absolute time followed by relative time.
+func (lc locale) timeWithRelative(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", formatTime(t, lc.loc), lc.relative(t))
+}
+
+// chatLocale returns the rendering preferences of chat.
+func (s *Server) chatLocale(ctx context.Context, chatID int64) locale {
+	return locale{
+		loc:  s.chatLocation(ctx, chatID),
+		lang: s.chatLanguage(ctx, chatID),
+		now:  s.clock.Now(),
+	}
+}
+
+// chatLocation returns the timezone of chat, or server timezone if not set.
+func (s *Server) chatLocation(ctx context.Context, chatID int64) *time.Location {
+	log := logging.FromContext(ctx)
+
+	name, err := s.db.getChatTimezone(chatID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return time.Local
+	} else if name == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Warning("Invalid timezone", "name", name, "error", err)
+		return time.Local
+	}
+
+	return loc
+}
+
+// chatLanguage returns the language of chat, or default language if not set.
+func (s *Server) chatLanguage(ctx context.Context, chatID int64) string {
+	log := logging.FromContext(ctx)
+
+	lang, err := s.db.getChatLanguage(chatID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return i18n.Default
+	} else if lang == "" {
+		return i18n.Default
+	}
+
+	return lang
+}
+
+// detectChatLanguage stores the language of sender as chat language if the chat has none.
+func (s *Server) detectChatLanguage(ctx context.Context, message *tgbot.Message) {
+	log := logging.FromContext(ctx)
+
+	if message.From == nil {
+		return
+	}
+
+	lang := i18n.Match(message.From.LanguageCode)
+	if lang == "" {
+		return
+	}
+
+	if _, err := s.db.Exec(
+		"INSERT IGNORE INTO languages (chatID, lang) VALUES (?, ?);",
+		message.Chat.ID, lang,
+	); err != nil {
+		log.Error("Database error", "error", err)
+	}
+}
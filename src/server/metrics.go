@@ -0,0 +1,15 @@
+package server
+
+import "github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
+
+var (
+	fanoutLatency = metrics.NewHistogram(
+		"notifier_notification_fanout_seconds",
+		"Latency from receiving a hub feed to notifying every chat.",
+		[]float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	)
+	diligentSchedulers = metrics.NewGauge(
+		"notifier_diligent_schedulers_active",
+		"Diligent schedulers waiting for or polling upcoming lives.",
+	)
+)
@@ -1,42 +1,76 @@
 package server
 
 import (
+	"context"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
-	"github.com/golang/glog"
 	"google.golang.org/api/youtube/v3"
 )
 
-func (s *Server) sendNotices(video *ytapi.Video) {
+// noticeVideoParts are the video resource parts required by notices,
+// including the parts which restrictions are detected from.
+var noticeVideoParts = []string{"snippet", "liveStreamingDetails", "contentDetails", "status"}
+
+func (s *Server) sendNotices(ctx context.Context, video *ytapi.Video) {
+	log := logging.FromContext(ctx).With("videoID", video.Id)
+
 	// Query chats that subscribed channel.
 	chats, err := s.db.getChatsByChannelID(video.Snippet.ChannelId)
 	if err != nil {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		return
 	}
 
-	// Insert or ignore new rows to notices table.
+	// Sample viewers while it's a live live.
+	s.updatePeakViewers(ctx, video)
+
+	// Also notify chats which subscribed featured guest channels.
+	guests, err := s.guestChats(video)
+	if err != nil {
+		log.Error("Database error", "error", err)
+	}
+
+	// Channels subscribed by each chat, whose filters decide the notice.
+	subscribed := make(map[int64][]string)
 	for _, c := range chats {
-		b, err := s.applyFilters(c.id, video)
-		if err != nil {
-			glog.Error(err)
-			continue
-		} else if !b { // Filter out
-			continue
+		subscribed[c.id] = []string{video.Snippet.ChannelId}
+	}
+	for chatID, channels := range guests {
+		for _, ch := range channels {
+			subscribed[chatID] = append(subscribed[chatID], ch.id)
 		}
+	}
 
-		if _, err := s.db.Exec(
-			"INSERT IGNORE INTO notices (videoID, chatID, messageID) VALUES (?, ?, ?);",
-			video.Id, c.id, -1,
-		); err != nil {
-			glog.Error(err)
+	// Insert or ignore new rows to notices table.
+	// Completed lives only update existing notices, which are kept to retry failed summaries.
+	if !ytapi.IsCompletedLiveBroadcast(video) {
+		s.reviseSchedule(ctx, video)
+
+		for chatID, channelIDs := range subscribed {
+			b, err := s.passFilters(ctx, chatID, channelIDs, video)
+			if err != nil {
+				log.Error("Database error", "error", err)
+				continue
+			} else if !b { // Filter out
+				continue
+			}
+
+			if _, err := s.db.Exec(
+				"INSERT IGNORE INTO notices (videoID, chatID, messageID) VALUES (?, ?, ?);",
+				video.Id, chatID, -1,
+			); err != nil {
+				log.Error("Database error", "error", err)
+			}
 		}
 	}
 
 	// Query notice rows according to video id.
 	notices, err := s.db.getNoticesByVideoID(video.Id)
 	if err != nil {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		return
 	}
 
@@ -44,57 +78,163 @@ func (s *Server) sendNotices(video *ytapi.Video) {
 	for _, n := range notices {
 		show, err := s.showRecordButton(n.chatID, video)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 		}
 
-		if n.messageID == -1 {
-			// If this chat still not being notified, send new notice.
-			msgConfig := tgbot.NewMessage(n.chatID, newNotifyMessageText(video))
+		lc := s.chatLocale(ctx, n.chatID)
 
-			if show {
-				markup, _ := s.newRecordButtonMarkup(video.Id)
-				msgConfig.ReplyMarkup = markup
-			}
+		var label string
+		if channels, ok := guests[n.chatID]; ok {
+			label = newFeaturingText(channels, lc)
+		}
+
+		text := s.chatNotifyMessageText(ctx, n.chatID, video, label, lc)
 
-			// Send message
-			message, err := s.tgSend(msgConfig)
+		if n.messageID == -1 {
+			// If this chat still not being notified, send new notice.
+			message, photo, err := s.sendNotice(ctx, n.chatID, video, text, label, show, lc)
 			if err != nil {
 				continue
 			}
 
+			log.Info("Notice sent", "chatID", n.chatID, "messageID", message.MessageID, "photo", photo)
+
 			n.messageID = message.MessageID
 			if _, err := s.db.Exec(
 				"UPDATE notices SET messageID = ? WHERE videoID = ? AND chatID = ?;",
 				n.messageID, n.videoID, n.chatID,
 			); err != nil {
-				glog.Error(err)
+				log.Error("Database error", "error", err)
+			}
+
+			if photo {
+				if _, err := s.db.Exec(
+					"INSERT IGNORE INTO photoNotices (videoID, chatID) VALUES (?, ?);",
+					n.videoID, n.chatID,
+				); err != nil {
+					log.Error("Database error", "error", err)
+				}
 			}
+		} else if n.photo {
+			// If this chat has be notified by photo, edit its caption.
+			editCaptionConfig := tgbot.NewEditMessageCaption(n.chatID, n.messageID, noticeCaption(text, label, video, lc))
+
+			if show {
+				markup, _ := s.newRecordButtonMarkup(video.Id, lc)
+				editCaptionConfig.ReplyMarkup = markup
+			}
+
+			s.tgSend(ctx, editCaptionConfig)
 		} else {
 			// If this chat has be notified, edit existing notice.
-			editMsgConfig := tgbot.NewEditMessageText(n.chatID, n.messageID, newNotifyMessageText(video))
+			editMsgConfig := tgbot.NewEditMessageText(n.chatID, n.messageID, text)
 
 			if show {
-				markup, _ := s.newRecordButtonMarkup(video.Id)
+				markup, _ := s.newRecordButtonMarkup(video.Id, lc)
 				editMsgConfig.ReplyMarkup = markup
 			}
 
-			s.tgSend(editMsgConfig)
+			s.tgSend(ctx, editMsgConfig)
 		}
 	}
 
 	// It's a completed live.
 	if ytapi.IsCompletedLiveBroadcast(video) {
+		// Reply stream ended summary to notified chats.
+		retry, err := s.sendSummaries(ctx, video)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			return
+		}
+
 		// Tag it as completed in videos table.
-		_, err := s.db.Exec("UPDATE videos SET completed = ? WHERE id = ?;", true, video.Id)
+		_, err = s.db.Exec("UPDATE videos SET completed = ? WHERE id = ?;", true, video.Id)
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
+		}
+
+		// Remove it from notices table, except chats whose summaries will be retried.
+		for _, n := range notices {
+			if retry[n.chatID] {
+				continue
+			}
+
+			if _, err := s.db.Exec("DELETE FROM notices WHERE videoID = ? AND chatID = ?;", video.Id, n.chatID); err != nil {
+				log.Error("Database error", "error", err)
+			}
+
+			if _, err := s.db.Exec("DELETE FROM photoNotices WHERE videoID = ? AND chatID = ?;", video.Id, n.chatID); err != nil {
+				log.Error("Database error", "error", err)
+			}
+		}
+
+		// Statistics are kept until every summary is sent.
+		if len(retry) != 0 {
+			return
+		}
+
+		if _, err := s.db.Exec("DELETE FROM calendarEvents WHERE videoID = ?;", video.Id); err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		// Viewers samples & peak are only used in summaries.
+		if _, err := s.db.Exec("DELETE FROM viewers WHERE videoID = ?;", video.Id); err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		if _, err := s.db.Exec("DELETE FROM streamStats WHERE videoID = ?;", video.Id); err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		s.forgetFeaturedChannels(video.Id)
+	}
+}
+
+// passFilters reports whether video passes filters of any channel
+// subscribed by chat, so a guest appearance is suppressed only if
+// every featured channel the chat subscribed rejects it.
+func (s *Server) passFilters(ctx context.Context, chatID int64, channelIDs []string, video *ytapi.Video) (bool, error) {
+	for _, channelID := range channelIDs {
+		if pass, err := s.applyFilters(ctx, chatID, channelID, video); err != nil || pass {
+			return pass, err
+		}
+	}
+
+	return false, nil
+}
+
+// sendNotice sends a new notice, as a thumbnail photo if the chat prefers.
+// Label is kept in the caption if it fits.
+// It reports whether the notice is sent as a photo.
+func (s *Server) sendNotice(ctx context.Context, chatID int64, video *ytapi.Video, text, label string, show bool, lc locale) (tgbot.Message, bool, error) {
+	photo, err := s.db.isPhotoChat(chatID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Database error", "error", err)
+	}
+
+	if thumbnail := ytapi.BestThumbnail(video); photo && thumbnail != "" {
+		photoConfig := tgbot.NewPhotoShare(chatID, thumbnail, noticeCaption(text, label, video, lc))
+
+		if show {
+			markup, _ := s.newRecordButtonMarkup(video.Id, lc)
+			photoConfig.ReplyMarkup = markup
 		}
 
-		// Remove it from notices table.
-		if _, err := s.db.Exec("DELETE FROM notices WHERE videoID = ?;", video.Id); err != nil {
-			glog.Error(err)
+		// Fall back to text message if Telegram can not fetch the thumbnail.
+		if message, err := s.tgSend(ctx, photoConfig); err == nil {
+			return message, true, nil
 		}
 	}
+
+	msgConfig := tgbot.NewMessage(chatID, text)
+
+	if show {
+		markup, _ := s.newRecordButtonMarkup(video.Id, lc)
+		msgConfig.ReplyMarkup = markup
+	}
+
+	message, err := s.tgSend(ctx, msgConfig)
+	return message, false, err
 }
 
 func (s *Server) showRecordButton(chatID int64, video *youtube.Video) (bool, error) {
@@ -157,3 +297,23 @@ func (s *Server) isRecordExist(chatID int64, videoID string) (bool, error) {
 		return exist, nil
 	}
 }
+
+// reviseSchedule updates start time of video & its calendar event if it's rescheduled,
+// no matter the update comes from hub, schedulers or trackers.
+func (s *Server) reviseSchedule(ctx context.Context, video *ytapi.Video) {
+	log := logging.FromContext(ctx)
+
+	t, err := time.Parse(time.RFC3339, video.LiveStreamingDetails.ScheduledStartTime)
+	if err != nil {
+		return
+	}
+
+	if _, err := s.db.Exec("UPDATE videos SET startTime = ? WHERE id = ?;", t.Unix(), video.Id); err != nil {
+		log.Error("Database error", "error", err)
+		return
+	}
+
+	if err := s.db.reviseCalendarEvent(video.Id, t.Unix(), s.clock.Now()); err != nil {
+		log.Error("Database error", "error", err)
+	}
+}
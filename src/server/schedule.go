@@ -0,0 +1,279 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+)
+
+type ScheduleRange int
+
+const (
+	AllRange ScheduleRange = iota
+	TodayRange
+	TomorrowRange
+	WeekRange
+)
+
+var scheduleRanges = map[string]ScheduleRange{
+	"today":    TodayRange,
+	"tomorrow": TomorrowRange,
+	"week":     WeekRange,
+}
+
+// Maximum number of lives listed in a schedule page.
+const scheduleListLength = 10
+
+// bounds returns the time range of schedule in loc, to is zero if unbounded.
+func (r ScheduleRange) bounds(now time.Time, loc *time.Location) (from, to time.Time) {
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	switch r {
+	case TodayRange:
+		return midnight, midnight.AddDate(0, 0, 1)
+	case TomorrowRange:
+		return midnight.AddDate(0, 0, 1), midnight.AddDate(0, 0, 2)
+	case WeekRange:
+		return midnight, midnight.AddDate(0, 0, 7)
+	default:
+		return midnight, time.Time{}
+	}
+}
+
+func (r ScheduleRange) name(lc locale) string {
+	switch r {
+	case TodayRange:
+		return lc.raw("schedule.today")
+	case TomorrowRange:
+		return lc.raw("schedule.tomorrow")
+	case WeekRange:
+		return lc.raw("schedule.week")
+	default:
+		return lc.raw("schedule.all")
+	}
+}
+
+type scheduleItem struct {
+	id, title    string
+	channelID    string
+	channelTitle string
+	startTime    int64
+	live         bool
+}
+
+// scheduleItems queries lives notified to chat, lives are placed before upcoming lives.
+// channelID is optional.
+func (s *Server) scheduleItems(chatID int64, channelID string, r ScheduleRange, loc *time.Location) ([]scheduleItem, error) {
+	var results []scheduleItem
+
+	query := "SELECT videos.id, videos.title, videos.channelID, videos.channelTitle, videos.startTime " +
+		"FROM notices INNER JOIN videos ON notices.videoID = videos.id " +
+		"WHERE notices.chatID = ?"
+	args := []interface{}{chatID}
+
+	if channelID != "" {
+		query += " AND videos.channelID = ?"
+		args = append(args, channelID)
+	}
+
+	err := s.db.queryResults(
+		&results,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*scheduleItem)
+			return rows.Scan(&r.id, &r.title, &r.channelID, &r.channelTitle, &r.startTime)
+		},
+		query+";",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	from, to := r.bounds(now, loc)
+
+	var items []scheduleItem
+
+	s.trackerMutex.Lock()
+	for _, item := range results {
+		t := time.Unix(item.startTime, 0)
+
+		if s.trackerTable[item.id] {
+			// Lives being tracked are streaming now.
+			item.live = true
+			items = append(items, item)
+		} else if !t.Before(now) && !t.Before(from) && (to.IsZero() || t.Before(to)) {
+			items = append(items, item)
+		}
+	}
+	s.trackerMutex.Unlock()
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].live != items[j].live {
+			return items[i].live
+		}
+		return items[i].startTime < items[j].startTime
+	})
+
+	return items, nil
+}
+
+// renderSchedulePage renders lives under section headers, a header is
+// repeated when a page starts in the middle of a section.
+func renderSchedulePage(header string, items []scheduleItem, lc locale) string {
+	var b strings.Builder
+	b.WriteString(header)
+
+	section := ""
+	for _, item := range items {
+		t := time.Unix(item.startTime, 0)
+
+		var title, when string
+		if item.live {
+			title = lc.raw("schedule.now_streaming")
+			when = lc.relative(t)
+		} else {
+			title = formatScheduleDay(t, lc)
+			when = fmt.Sprintf("%s (%s)", t.In(lc.loc).Format("15:04"), lc.relative(t))
+		}
+
+		if title != section {
+			section = title
+			b.WriteString("\n\n")
+			b.WriteString(tgbot.BordText(tgbot.EscapeText(section)))
+		}
+
+		b.WriteString(fmt.Sprintf(
+			"\n%s %s\n%s",
+			tgbot.EscapeText(when),
+			tgbot.ItalicText(tgbot.EscapeText(item.channelTitle)),
+			tgbot.InlineLink(tgbot.BordText(tgbot.EscapeText(item.title)), ytVideoURLPrefix+item.id),
+		))
+	}
+
+	return b.String()
+}
+
+// formatScheduleDay formats day of t in chat timezone, e.g. "10/19 (Mon)".
+func formatScheduleDay(t time.Time, lc locale) string {
+	t = t.In(lc.loc)
+	return fmt.Sprintf("%s (%s)", t.Format("01/02"), lc.raw(fmt.Sprintf("weekday.%d", t.Weekday())))
+}
+
+// paginateSchedule splits lives into pages, each page has at most
+// scheduleListLength lives and fits in a message.
+func paginateSchedule(header string, items []scheduleItem, lc locale) [][]scheduleItem {
+	var pages [][]scheduleItem
+	var page []scheduleItem
+
+	for _, item := range items {
+		next := append(page[:len(page):len(page)], item)
+
+		if len(page) != 0 && (len(next) > scheduleListLength ||
+			len([]rune(renderSchedulePage(header, next, lc))) > maxMessageLength) {
+			pages = append(pages, page)
+			next = []scheduleItem{item}
+		}
+
+		page = next
+	}
+
+	if len(page) != 0 {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// newSchedule renders a page of chat schedule & its pagination keyboard.
+// The keyboard is nil if there is only one page.
+func (s *Server) newSchedule(chatID int64, channelID string, r ScheduleRange, page int, lc locale) (string, *tgbot.InlineKeyboardMarkup, error) {
+	items, err := s.scheduleItems(chatID, channelID, r, lc.loc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	header := fmt.Sprintf("%s · %s", lc.raw("schedule.title"), r.name(lc))
+	if channelID != "" {
+		title, err := s.db.getChannelTitle(channelID)
+		if err != nil {
+			return "", nil, err
+		}
+		header += " · " + title
+	}
+
+	pages := paginateSchedule(tgbot.BordText(tgbot.EscapeText(header)), items, lc)
+	if len(pages) == 0 {
+		return tgbot.BordText(tgbot.EscapeText(header)) + "\n\n" + lc.text("schedule.empty"), nil, nil
+	}
+
+	// Lives may be changed since last page turn.
+	if page >= len(pages) {
+		page = len(pages) - 1
+	} else if page < 0 {
+		page = 0
+	}
+
+	if len(pages) > 1 {
+		header += fmt.Sprintf(" (%d/%d)", page+1, len(pages))
+	}
+
+	text := renderSchedulePage(tgbot.BordText(tgbot.EscapeText(header)), pages[page], lc)
+
+	if len(pages) == 1 {
+		return text, nil, nil
+	}
+
+	newButton := func(text string, page int) tgbot.InlineKeyboardButton {
+		data := make(map[string]interface{})
+		data["type"] = Schedule
+		data["r"] = r
+		if channelID != "" {
+			data["cid"] = channelID
+		}
+		data["page"] = page
+		b, _ := json.Marshal(data)
+
+		return tgbot.NewInlineKeyboardButtonData(text, string(b))
+	}
+
+	var buttons []tgbot.InlineKeyboardButton
+
+	if page != 0 {
+		buttons = append(buttons, newButton("←", page-1))
+	}
+
+	buttons = append(buttons, newButton("↻", page))
+
+	if page != len(pages)-1 {
+		buttons = append(buttons, newButton("→", page+1))
+	}
+
+	markup := tgbot.NewInlineKeyboardMarkup(tgbot.NewInlineKeyboardRow(buttons...))
+
+	return text, &markup, nil
+}
+
+// matchSubscribedChannel finds channel by ID, URL or title.
+func matchSubscribedChannel(channels []Channel, query string) (Channel, bool) {
+	for _, ch := range channels {
+		if query == ch.id || strings.HasSuffix(strings.TrimSuffix(query, "/"), "/"+ch.id) {
+			return ch, true
+		}
+	}
+
+	for _, ch := range channels {
+		if strings.Contains(strings.ToLower(ch.title), strings.ToLower(query)) {
+			return ch, true
+		}
+	}
+
+	return Channel{}, false
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
@@ -8,44 +9,46 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
-	"github.com/golang/glog"
 	"google.golang.org/api/youtube/v3"
 )
 
-const updateFrequency = time.Hour
-
 func (s *Server) initScheduler() {
-	// Update all notifies first.
+	// Update all notifies & summaries first.
 	s.updateNotifies()
+	s.updateSummaries()
 
 	// Get initial waiting duration.
-	now := time.Now()
+	now := s.clock.Now()
 	next := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
 	for now.After(next) {
-		next = next.Add(updateFrequency)
+		next = next.Add(s.Schedule.UpdateFrequency.Duration)
 	}
 	dur := next.Sub(now)
 
 	// Start scheduler after initial waiting duration.
-	time.AfterFunc(dur, func() {
-		go s.regularScheduler()
-	})
+	s.clock.AfterFunc(dur, s.regularScheduler)
 }
 
 func (s *Server) regularScheduler() {
 	for {
 		s.updateNotifies()
+		s.updateSummaries()
 
 		// TODO: Update recorder status
 
 		// Wait for next period.
-		time.Sleep(updateFrequency)
+		s.clock.Sleep(s.Schedule.UpdateFrequency.Duration)
 	}
 }
 
 func (s *Server) updateNotifies() {
+	ctx := logging.WithCorrelationID(context.Background(), logging.NewID())
+	ctx = logging.NewContext(ctx, "scheduler", "regular")
+	log := logging.FromContext(ctx)
+
 	// Get all monitored video ids.
 	var videoIDs []string
 
@@ -59,52 +62,69 @@ func (s *Server) updateNotifies() {
 	)
 
 	if err != nil {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		return
 	}
 
 	// Request video resources from yt api
-	videos, err := s.yt.GetVideos(videoIDs, []string{"snippet", "liveStreamingDetails"})
+	videos, err := s.yt.GetVideos(videoIDs, noticeVideoParts)
 	if err != nil {
-		glog.Warning(err)
+		log.Warning("YouTube API error", "error", err)
 		return
 	}
 
+	log.Info("Update notices", "videos", len(videos))
+
 	for _, v := range videos {
 		// Send or update notifies.
-		go func(v *youtube.Video) {
-			s.sendNotices(v)
-			s.tryDiligentScheduler(v)
-		}(v)
+		v := v
+		s.clock.Go(func() {
+			ctx := logging.NewContext(ctx, "videoID", v.Id)
+
+			s.sendNotices(ctx, v)
+			s.tryDiligentScheduler(ctx, v)
+			s.tryLiveTracker(ctx, v)
+		})
 	}
 }
 
-func (s *Server) tryDiligentScheduler(video *ytapi.Video) {
+// tryDiligentScheduler starts a diligent scheduler if the video is going to start,
+// which inherits correlation ID of ctx.
+func (s *Server) tryDiligentScheduler(ctx context.Context, video *ytapi.Video) {
+	s.diligentMutex.Lock()
+	defer s.diligentMutex.Unlock()
+
 	if s.isDiligentCondition(video) {
 		s.diligentTable[video.Id] = true
+		diligentSchedulers.Inc()
 
 		t, _ := time.Parse(time.RFC3339, video.LiveStreamingDetails.ScheduledStartTime)
-		remains := time.Until(t)
+		remains := t.Sub(s.clock.Now())
 
 		videoID := video.Id
+		tier := s.Schedule.tier(video.Snippet.ChannelId)
 
 		// Run diligent scheduler
-		time.AfterFunc(getWaitingDuration(remains), func() {
-			go func() {
-				s.diligentScheduler(videoID)
-				delete(s.diligentTable, videoID)
-			}()
+		s.clock.AfterFunc(getWaitingDuration(remains, tier.Checkpoints), func() {
+			s.diligentScheduler(ctx, videoID, tier)
+
+			s.diligentMutex.Lock()
+			delete(s.diligentTable, videoID)
+			diligentSchedulers.Dec()
+			s.diligentMutex.Unlock()
 		})
 	}
 }
 
+// isDiligentCondition must be called with diligentMutex held.
 func (s *Server) isDiligentCondition(v *ytapi.Video) bool {
 	if ytapi.IsUpcomingLiveBroadcast(v) {
 		t, _ := time.Parse(time.RFC3339, v.LiveStreamingDetails.ScheduledStartTime)
-		remains := time.Until(t)
+		remains := t.Sub(s.clock.Now())
 
-		// Check is remaining time longer than update frequency & not in diligent table
-		if _, ok := s.diligentTable[v.Id]; remains <= updateFrequency && !ok {
+		// Check is remaining time within diligent window & not in diligent table
+		window := s.Schedule.tier(v.Snippet.ChannelId).Window.Duration
+		if _, ok := s.diligentTable[v.Id]; remains <= window && !ok {
 			return true
 		}
 	}
@@ -112,107 +132,131 @@ func (s *Server) isDiligentCondition(v *ytapi.Video) bool {
 	return false
 }
 
-func (s *Server) diligentScheduler(videoID string) {
-	glog.Info("Running " + ytVideoURLPrefix + videoID + " diligent scheduler")
+func (s *Server) diligentScheduler(ctx context.Context, videoID string, tier ScheduleTier) {
+	ctx = logging.NewContext(ctx, "scheduler", "diligent")
+	log := logging.FromContext(ctx)
+
+	log.Info("Running diligent scheduler", "url", ytVideoURLPrefix+videoID)
 
 	for {
-		time.Sleep(time.Second)
+		s.clock.Sleep(tier.Poll.Duration)
 
 		// Get video resource & update notifies.
-		v, err := s.yt.GetVideo(videoID, []string{"snippet", "liveStreamingDetails"})
+		v, err := s.yt.GetVideo(videoID, noticeVideoParts)
 		if err != nil {
-			glog.Warning(err)
+			log.Warning("YouTube API error", "error", err)
 			return
 		}
 
-		s.sendNotices(v)
+		s.sendNotices(ctx, v)
 
 		// Get remaining time
 		t, _ := time.Parse(time.RFC3339, v.LiveStreamingDetails.ScheduledStartTime)
-		remains := time.Until(t)
+		remains := t.Sub(s.clock.Now())
 
-		if remains > updateFrequency {
+		if remains > tier.Window.Duration {
 			// If still have enough time, stop diligent scheduler.
 			return
 		} else if ytapi.IsLiveLiveBroadcast(v) {
 			// If live already start, stop diligent scheduler & send notifies.
-			notices, err := s.db.getNoticesByVideoID(v.Id)
-			if err != nil {
-				glog.Error(err)
-				return
-			}
-
-			for _, n := range notices {
-				// Remove record button
-				go func() {
-					cfg := tgbot.NewEditMessageReplyMarkup(n.chatID, n.messageID,
-						tgbot.InlineKeyboardMarkup{InlineKeyboard: [][]tgbot.InlineKeyboardButton{{}}})
-					s.tgSend(cfg)
-				}()
-
-				msgConfig := tgbot.NewMessage(n.chatID, fmt.Sprintf(
-					"%s\n%s",
-					tgbot.EscapeText(v.Snippet.ChannelTitle+" is now live!"),
-					tgbot.InlineLink(
-						tgbot.BordText(tgbot.EscapeText(v.Snippet.Title)),
-						ytVideoURLPrefix+v.Id,
-					),
-				))
-				msgConfig.DisableWebPagePreview = true
-
-				s.tgSend(msgConfig)
-
-				go func(n Notice) {
-					time.Sleep(3 * time.Second)
-					s.sendDownloadRequest(v, n)
-				}(n)
-			}
+			// Viewers will be tracked by live tracker from now on.
+			s.tryLiveTracker(ctx, v)
+			s.sendLiveNotices(ctx, v)
 
 			return
 		}
 
-		time.Sleep(getWaitingDuration(remains))
+		s.clock.Sleep(getWaitingDuration(remains, tier.Checkpoints))
 
 		// WTF, scheduled start time has arrived but live still not started!
 		if remains <= 0 {
 			if (-remains)%30*time.Minute == 0 {
-				glog.Warning("Running " + ytVideoURLPrefix + v.Id + " tolerance section")
-				glog.Warning("Already " + (-remains).String() + " has elapsed")
+				log.Warning("Running tolerance section", "url", ytVideoURLPrefix+v.Id, "elapsed", (-remains).String())
 			}
 
 			// Well, lets wait for 30 more seconds.
-			time.Sleep(30 * time.Second)
+			s.clock.Sleep(30 * time.Second)
 		}
 	}
 }
 
-func getWaitingDuration(t time.Duration) time.Duration {
-	var interval = [...]time.Duration{30 * time.Minute, 15 * time.Minute, 5 * time.Minute, 1 * time.Minute, 10 * time.Second, 0}
+// sendLiveNotices notifies chats that the live started, then requests recorders.
+func (s *Server) sendLiveNotices(ctx context.Context, v *ytapi.Video) {
+	log := logging.FromContext(ctx)
+
+	notices, err := s.db.getNoticesByVideoID(v.Id)
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return
+	}
+
+	log.Info("Live started", "notices", len(notices))
+
+	for _, n := range notices {
+		n := n
+
+		// Remove record button
+		s.clock.Go(func() {
+			cfg := tgbot.NewEditMessageReplyMarkup(n.chatID, n.messageID,
+				tgbot.InlineKeyboardMarkup{InlineKeyboard: [][]tgbot.InlineKeyboardButton{{}}})
+			s.tgSend(ctx, cfg)
+		})
+
+		lc := s.chatLocale(ctx, n.chatID)
+
+		msgConfig := tgbot.NewMessage(n.chatID, fmt.Sprintf(
+			"%s\n%s",
+			fmt.Sprintf(lc.text("notice.now_live"), tgbot.EscapeText(v.Snippet.ChannelTitle)),
+			tgbot.InlineLink(
+				tgbot.BordText(tgbot.EscapeText(v.Snippet.Title)),
+				ytVideoURLPrefix+v.Id,
+			),
+		))
+		msgConfig.DisableWebPagePreview = true
+
+		s.tgSend(ctx, msgConfig)
 
-	for _, v := range interval {
-		if t > v {
-			return t - v
+		s.clock.Go(func() {
+			s.clock.Sleep(3 * time.Second)
+			s.sendDownloadRequest(ctx, v, n)
+		})
+	}
+}
+
+// getWaitingDuration returns the duration until the next checkpoint before start,
+// checkpoints should be descending.
+func getWaitingDuration(t time.Duration, checkpoints []Duration) time.Duration {
+	for _, v := range checkpoints {
+		if t > v.Duration {
+			return t - v.Duration
 		}
 	}
 
+	if t > 0 {
+		return t
+	}
+
 	return 0
 }
 
-func (s *Server) sendDownloadRequest(v *youtube.Video, n Notice) {
+func (s *Server) sendDownloadRequest(ctx context.Context, v *youtube.Video, n Notice) {
+	log := logging.FromContext(ctx).With("chatID", n.chatID)
+
 	eTitle := tgbot.EscapeText(v.Snippet.Title)
 	vURL := ytVideoURLPrefix + v.Id
+	lc := s.chatLocale(ctx, n.chatID)
 
 	var msgConfig tgbot.MessageConfig
 	var internalServerError tgbot.MessageConfig = tgbot.NewMessage(
 		n.chatID,
-		fmt.Sprintf("Record %s failed, internal server error", tgbot.InlineLink(eTitle, vURL)),
+		fmt.Sprintf(lc.text("record.internal"), tgbot.InlineLink(eTitle, vURL)),
 	)
 
 	defer func() {
 		if msgConfig != (tgbot.MessageConfig{}) {
 			msgConfig.DisableNotification = true
 			msgConfig.DisableWebPagePreview = true
-			s.tgSend(msgConfig)
+			s.tgSend(ctx, msgConfig)
 		}
 	}()
 
@@ -224,7 +268,7 @@ func (s *Server) sendDownloadRequest(v *youtube.Video, n Notice) {
 	).Scan(&exists)
 
 	if err != nil && err != sql.ErrNoRows {
-		glog.Error(err)
+		log.Error("Database error", "error", err)
 		msgConfig = internalServerError
 	} else if exists {
 		if r, ok := s.recorderTable[n.chatID]; ok {
@@ -235,39 +279,38 @@ func (s *Server) sendDownloadRequest(v *youtube.Video, n Notice) {
 			data["channelID"] = v.Snippet.ChannelId
 			data["videoID"] = v.Id
 
-			resp, err := r.Record(s.CallbackUrl()+"/recorder", data)
+			resp, err := r.Record(ctx, s.CallbackUrl()+"/recorder", data)
 
 			if err != nil {
 				if err.(*url.Error).Timeout() {
 					msgConfig = tgbot.NewMessage(
 						n.chatID,
-						fmt.Sprintf("Record %s failed, connection timeout", tgbot.InlineLink(eTitle, vURL)),
+						fmt.Sprintf(lc.text("record.timeout"), tgbot.InlineLink(eTitle, vURL)),
 					)
 				} else {
-					glog.Error(err)
 					msgConfig = internalServerError
 				}
 			} else if resp.StatusCode != http.StatusOK {
 				respBody, err := ioutil.ReadAll(resp.Body)
 				if err != nil {
-					glog.Error(err)
+					log.Error("Failed to read recorder response", "error", err)
 					msgConfig = internalServerError
 					return
 				}
 
-				fmt.Println(string(respBody))
+				log.Warning("Recorder rejected record", "status", resp.StatusCode, "body", string(respBody))
 				msgConfig = tgbot.NewMessage(
 					n.chatID,
-					fmt.Sprintf("Record request failed with status code %d, please check your recorder", resp.StatusCode),
+					fmt.Sprintf(lc.text("record.status"), resp.StatusCode),
 				)
 			} else {
 				msgConfig = tgbot.NewMessage(
 					n.chatID,
-					fmt.Sprintf("Start recording %s", tgbot.InlineLink(tgbot.EscapeText(v.Snippet.Title), ytVideoURLPrefix+v.Id)),
+					fmt.Sprintf(lc.text("record.started"), tgbot.InlineLink(eTitle, vURL)),
 				)
 			}
 		} else {
-			msgConfig = tgbot.NewMessage(n.chatID, "Recorder unavailable for you")
+			msgConfig = tgbot.NewMessage(n.chatID, lc.text("record.unavailable"))
 		}
 	}
 }
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+)
+
+const channelSearchLimit = 5
+
+// chSearchHandler searches channels by query and lets chat pick one to subscribe.
+func (s *Server) chSearchHandler(ctx context.Context, chatID int64, query string, lc locale) {
+	log := logging.FromContext(ctx)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	channels, err := s.yt.SearchChannels(query, channelSearchLimit, []string{"snippet", "statistics"})
+	if err != nil {
+		log.Warning("YouTube API error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("search.failed"))
+		msgConfig.DisableWebPagePreview = true
+		return
+	} else if len(channels) == 0 {
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("search.empty"), tgbot.InlineCode(tgbot.EscapeText(query))))
+		msgConfig.DisableWebPagePreview = true
+		return
+	}
+
+	lines := []string{fmt.Sprintf(lc.text("search.title"), tgbot.InlineCode(tgbot.EscapeText(query)))}
+	var rows [][]tgbot.InlineKeyboardButton
+
+	for i, c := range channels {
+		line := fmt.Sprintf(
+			"%d\\. %s",
+			i+1,
+			tgbot.InlineLink(tgbot.EscapeText(c.Snippet.Title), ytChannelURLPrefix+c.Id),
+		)
+
+		// Link thumbnail first, so web page preview shows the top match.
+		if thumbnail := bestChannelThumbnail(c); thumbnail != "" {
+			line = fmt.Sprintf("%s %s", tgbot.InlineLink("🖼", thumbnail), line)
+		}
+
+		line = fmt.Sprintf("%s · %s", line, subscriberCountText(c, lc))
+		lines = append(lines, line)
+
+		data := make(map[string]interface{})
+		data["type"] = Subscribe
+		data["cid"] = c.Id
+		b, _ := json.Marshal(data)
+
+		button := tgbot.NewInlineKeyboardButtonData(fmt.Sprintf("%d. %s", i+1, c.Snippet.Title), string(b))
+		rows = append(rows, tgbot.NewInlineKeyboardRow(button))
+	}
+
+	msgConfig = tgbot.NewMessage(chatID, strings.Join(lines, "\n"))
+	msgConfig.ReplyMarkup = tgbot.NewInlineKeyboardMarkup(rows...)
+}
+
+// bestChannelThumbnail returns url of the largest thumbnail of channel.
+func bestChannelThumbnail(c *ytapi.Channel) string {
+	if c.Snippet.Thumbnails == nil {
+		return ""
+	}
+
+	for _, t := range []*ytapi.Thumbnail{
+		c.Snippet.Thumbnails.High,
+		c.Snippet.Thumbnails.Medium,
+		c.Snippet.Thumbnails.Default,
+	} {
+		if t != nil && t.Url != "" {
+			return t.Url
+		}
+	}
+
+	return ""
+}
+
+// subscriberCountText returns abbreviated subscriber count of channel, e.g. "1.2M".
+func subscriberCountText(c *ytapi.Channel, lc locale) string {
+	if c.Statistics == nil || c.Statistics.HiddenSubscriberCount {
+		return lc.text("search.hidden")
+	}
+
+	n := c.Statistics.SubscriberCount
+
+	var count string
+	switch {
+	case n >= 1000000:
+		count = strconv.FormatFloat(float64(n)/1000000, 'f', 1, 64) + "M"
+	case n >= 1000:
+		count = strconv.FormatFloat(float64(n)/1000, 'f', 1, 64) + "K"
+	default:
+		count = strconv.FormatUint(n, 10)
+	}
+
+	return fmt.Sprintf(lc.text("search.subscribers"), tgbot.EscapeText(count))
+}
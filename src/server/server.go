@@ -1,38 +1,62 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/clock"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/hub"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/recorder"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
-	"github.com/golang/glog"
 )
 
 // Server is a main server which integrated all function in this project.
 type Server struct {
 	Setting
 
-	hub *hub.Client
-	tg  *tgbot.TgBot
-	yt  *ytapi.YtAPI
+	hub hub.Subscriber
+	tg  tgbot.Sender
+	yt  ytapi.Client
 	db  *database
 
+	// clock drives schedulers & trackers, it's faked by simulations.
+	clock clock.Clock
+
 	serveMux *http.ServeMux
 
 	tgUpdatesCh tgbot.UpdatesChannel
 	hubFeedsCh  hub.FeedsChannel
 
+	diligentMutex sync.Mutex
 	diligentTable map[string]bool
 	recorderTable map[int64]recorder.Recorder
+
+	trackerMutex sync.Mutex
+	trackerTable map[string]bool
+
+	inlineMutex sync.Mutex
+	inlineCache map[string]inlineCacheEntry
+
+	featuredMutex sync.Mutex
+	featuredCache map[string]featuredEntry
+
+	healthMutex sync.Mutex
+	healthCache map[string]healthCheck
 }
 
 // NewServer returns a pointer to a new `Server` object.
 func NewServer(setting Setting) (*Server, error) {
+	if err := setting.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Create service multiplexer
 	mux := new(http.ServeMux)
 
@@ -57,15 +81,28 @@ func NewServer(setting Setting) (*Server, error) {
 	// Hook tgbot service
 	tgUpdatesCh := tg.ListenForWebhook("/tgbot", mux)
 
+	return newServer(setting, mux, hub, hubFeedsCh, tg, tgUpdatesCh, yt, db, clock.Real), nil
+}
+
+// newServer returns a pointer to a new `Server` object with given dependencies.
+func newServer(
+	setting Setting, mux *http.ServeMux,
+	hub hub.Subscriber, hubFeedsCh hub.FeedsChannel,
+	tg tgbot.Sender, tgUpdatesCh tgbot.UpdatesChannel,
+	yt ytapi.Client, db *database,
+	clk clock.Clock,
+) *Server {
 	// Initialize smain server
 	server := &Server{
 		Setting: setting,
 
 		hub: hub,
-		tg:  tg,
-		yt:  yt,
+		tg:  tgbot.Metered(tg),
+		yt:  ytapi.Metered(yt),
 		db:  db,
 
+		clock: clk,
+
 		serveMux: mux,
 
 		tgUpdatesCh: tgUpdatesCh,
@@ -73,16 +110,31 @@ func NewServer(setting Setting) (*Server, error) {
 
 		diligentTable: make(map[string]bool),
 		recorderTable: make(map[int64]recorder.Recorder),
+		trackerTable:  make(map[string]bool),
+		inlineCache:   make(map[string]inlineCacheEntry),
+		featuredCache: make(map[string]featuredEntry),
+		healthCache:   make(map[string]healthCheck),
 	}
 
 	// Hook recoder service
 	mux.HandleFunc("/recorder", server.recorderHandler)
 
-	return server, nil
+	// Hook ICS calendar feed service
+	mux.HandleFunc(calendarPathPrefix, server.calendarFeedHandler)
+
+	// Hook Prometheus metrics service
+	mux.Handle("/metrics", metrics.Handler())
+
+	// Hook health check services
+	mux.HandleFunc("/healthz", server.healthzHandler)
+	mux.HandleFunc("/readyz", server.readyzHandler)
+
+	return server
 }
 
 func (s *Server) initServer() {
 	s.recoverSubscriptions()
+	s.refreshChannelHandles()
 
 	// Run hub subscription requests.
 	go s.hub.Start()
@@ -100,7 +152,7 @@ func (s *Server) initServer() {
 func (s *Server) recoverSubscriptions() {
 	channels, err := s.db.getChannels()
 	if err != nil {
-		glog.Fatalln(err)
+		logging.With("error", err).Fatal("Failed to recover subscriptions")
 	}
 
 	for _, ch := range channels {
@@ -121,7 +173,7 @@ func (s *Server) getRecorders() {
 	)
 
 	if err != nil {
-		glog.Error(err)
+		logging.With("error", err).Error("Database error")
 		return
 	}
 
@@ -135,15 +187,16 @@ func (s *Server) ListenAndServe() {
 	s.initServer()
 
 	// Start server
-	glog.Info("Starting server on port", s.ServicePort)
-	glog.Fatalln(http.ListenAndServe(fmt.Sprintf(":%d", s.ServicePort), s.serveMux))
+	logging.With("port", s.ServicePort).Info("Starting server")
+	err := http.ListenAndServe(fmt.Sprintf(":%d", s.ServicePort), s.serveMux)
+	logging.With("error", err).Fatal("Server stopped")
 }
 
 // Close stops the main server and run clean up procedures.
 func (s *Server) Close() {
 	channels, err := s.db.getChannels()
 	if err != nil {
-		glog.Fatalln(err)
+		logging.With("error", err).Fatal("Failed to unsubscribe channels")
 	}
 
 	for _, ch := range channels {
@@ -156,39 +209,83 @@ func (s *Server) handlerRelay() {
 		select {
 		// Tgbot handler
 		case update := <-s.tgUpdatesCh:
-			if update.Message != nil {
-				if update.Message.ReplyToMessage != nil {
-					go func() {
-						err := s.filterReplyHandler(update)
-						if err != nil {
-							glog.Error(err)
-						}
-					}()
-				} else if update.Message.Text != "" {
-					elements := strings.Fields(update.Message.Text)
-					switch elements[0] {
-					case "/add":
-						go s.chAddHandler(update)
-					case "/list":
-						go s.chListHandler(update)
-					case "/remind":
-						go s.remindHandler(update)
-					case "/schedule":
-						go s.scheduleHandler(update)
-					case "/filter":
-						go s.filterHandler(update)
-					case "~autorc":
-						go s.autoRecordHandler(update)
-					case "~dl":
-						go s.downloadHandler(update)
-					}
-				}
-			} else if update.CallbackQuery != nil {
-				go s.callbackHandler(update)
-			}
+			s.dispatchUpdate(update)
 		// Hub notifies handler
 		case feed := <-s.hubFeedsCh:
-			go s.noticeHandler(feed)
+			s.dispatchFeed(feed)
 		}
 	}
 }
+
+// dispatchUpdate handles a tgbot update in a new goroutine started by clock.
+func (s *Server) dispatchUpdate(update tgbot.Update) {
+	ctx := logging.WithCorrelationID(context.Background(), logging.NewID())
+	ctx = logging.NewContext(ctx, "updateID", update.UpdateID)
+
+	if update.Message != nil {
+		if update.Message.ReplyToMessage != nil {
+			s.clock.Go(func() {
+				err := s.filterReplyHandler(ctx, update)
+				if err != nil {
+					logging.FromContext(ctx).Error("Failed to handle filter reply", "error", err)
+				}
+			})
+		} else if update.Message.Text != "" {
+			s.clock.Go(func() { s.commandHandler(ctx, update) })
+		}
+	} else if update.CallbackQuery != nil {
+		s.clock.Go(func() { s.callbackHandler(ctx, update) })
+	} else if update.InlineQuery != nil {
+		s.clock.Go(func() { s.inlineQueryHandler(ctx, update) })
+	}
+}
+
+// dispatchFeed handles a hub feed in a new goroutine started by clock.
+func (s *Server) dispatchFeed(feed hub.Feed) {
+	ctx := logging.WithCorrelationID(context.Background(), feed.ID)
+	s.clock.Go(func() { s.noticeHandler(ctx, feed) })
+}
+
+// commandHandler dispatches command message to corresponding handler.
+func (s *Server) commandHandler(ctx context.Context, update tgbot.Update) {
+	elements := strings.Fields(update.Message.Text)
+	if len(elements) == 0 {
+		return
+	}
+
+	logging.FromContext(ctx).Info("Handle command", "command", elements[0], "chatID", update.Message.Chat.ID)
+
+	// Initialize language of new chats from sender.
+	s.detectChatLanguage(ctx, update.Message)
+
+	switch elements[0] {
+	case "/add":
+		s.chAddHandler(ctx, update)
+	case "/list":
+		s.chListHandler(ctx, update)
+	case "/remind":
+		s.remindHandler(ctx, update)
+	case "/schedule":
+		s.scheduleHandler(ctx, update)
+	case "/timezone":
+		s.timezoneHandler(ctx, update)
+	case "/language":
+		s.languageHandler(ctx, update)
+	case "/template":
+		s.templateHandler(ctx, update)
+	case "/photo":
+		s.photoHandler(ctx, update)
+	case "/optout":
+		s.optOutHandler(ctx, update)
+	case "/guest":
+		s.guestHandler(ctx, update)
+	case "/calendar":
+		s.calendarHandler(ctx, update)
+	case "/filter":
+		s.filterHandler(ctx, update)
+	case "~autorc":
+		s.autoRecordHandler(ctx, update)
+	case "~dl":
+		s.downloadHandler(ctx, update)
+	}
+}
@@ -1,6 +1,11 @@
 package server
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
 
 type Setting struct {
 	Host         string `json:"host"`
@@ -12,8 +17,134 @@ type Setting struct {
 	YtAPIKey string `json:"yt_api_key"`
 	CertFile string `json:"ssl_cert"`
 	KeyFile  string `json:"ssl_key"`
+
+	Schedule ScheduleSetting `json:"schedule"`
 }
 
 func (s Setting) CallbackUrl() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.CallbackPort)
 }
+
+// Validate fills defaults of omitted fields, then checks setting is usable.
+func (s *Setting) Validate() error {
+	return s.Schedule.validate()
+}
+
+// defaultScheduleTier is the tier of channels without override.
+const defaultScheduleTier = "default"
+
+// ScheduleSetting is the cadence of schedulers.
+//
+// Every channel is polled by regular scheduler per UpdateFrequency.
+// Once an upcoming live is within Window of its tier, a diligent scheduler
+// waits until each of Checkpoints before start, then polls per Poll.
+type ScheduleSetting struct {
+	UpdateFrequency Duration                `json:"update_frequency"`
+	Tiers           map[string]ScheduleTier `json:"tiers"`
+	// Channels maps channel ID to its tier name.
+	Channels map[string]string `json:"channels"`
+}
+
+// ScheduleTier is the diligent polling cadence of a group of channels.
+type ScheduleTier struct {
+	Window      Duration   `json:"window"`
+	Checkpoints []Duration `json:"checkpoints"`
+	Poll        Duration   `json:"poll"`
+}
+
+// tier returns the tier of channel.
+func (s ScheduleSetting) tier(channelID string) ScheduleTier {
+	if name, ok := s.Channels[channelID]; ok {
+		return s.Tiers[name]
+	}
+
+	return s.Tiers[defaultScheduleTier]
+}
+
+func (s *ScheduleSetting) validate() error {
+	if s.UpdateFrequency.Duration == 0 {
+		s.UpdateFrequency.Duration = time.Hour
+	} else if s.UpdateFrequency.Duration < time.Minute {
+		return fmt.Errorf("schedule: update_frequency %s is shorter than 1m", s.UpdateFrequency)
+	}
+
+	if s.Tiers == nil {
+		s.Tiers = make(map[string]ScheduleTier)
+	}
+	if _, ok := s.Tiers[defaultScheduleTier]; !ok {
+		s.Tiers[defaultScheduleTier] = ScheduleTier{}
+	}
+
+	for name, tier := range s.Tiers {
+		if tier.Window.Duration == 0 {
+			tier.Window = s.UpdateFrequency
+		}
+		if tier.Checkpoints == nil {
+			for _, d := range []time.Duration{30 * time.Minute, 15 * time.Minute, 5 * time.Minute, time.Minute, 10 * time.Second} {
+				tier.Checkpoints = append(tier.Checkpoints, Duration{d})
+			}
+		}
+		if tier.Poll.Duration == 0 {
+			tier.Poll.Duration = time.Second
+		}
+
+		if err := tier.validate(s.UpdateFrequency.Duration); err != nil {
+			return fmt.Errorf("schedule: tier %q: %v", name, err)
+		}
+
+		s.Tiers[name] = tier
+	}
+
+	for channelID, name := range s.Channels {
+		if _, ok := s.Tiers[name]; !ok {
+			return fmt.Errorf("schedule: channel %s: unknown tier %q", channelID, name)
+		}
+	}
+
+	return nil
+}
+
+func (t ScheduleTier) validate(updateFrequency time.Duration) error {
+	if t.Window.Duration < updateFrequency {
+		// Lives starting between regular updates would be missed.
+		return fmt.Errorf("window %s is shorter than update_frequency %s", t.Window, updateFrequency)
+	} else if t.Poll.Duration <= 0 {
+		return errors.New("poll should be positive")
+	}
+
+	last := t.Window.Duration
+	for _, c := range t.Checkpoints {
+		if c.Duration <= 0 || c.Duration >= last {
+			return errors.New("checkpoints should be positive, descending & within window")
+		}
+		last = c.Duration
+	}
+
+	return nil
+}
+
+// Duration is a time.Duration which is a string like "1h30m" in JSON.
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON ...
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON ...
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = dur
+	return nil
+}
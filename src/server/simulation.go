@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/clock"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/hub"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/recorder"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+)
+
+const (
+	simChannelID    = "UCsimulationchannel00000"
+	simChannelTitle = "Simulation Channel"
+	simChatID       = -1000000000001
+)
+
+// Simulation runs a Server against fakes of hub, YouTube, Telegram & recorder,
+// driven by a fake clock, so the notification lifecycle runs in no time.
+// Only the database is real, it should be a scratch one.
+type Simulation struct {
+	clock    *clock.Fake
+	hub      *hub.Fake
+	feedsCh  hub.FeedsChannel
+	yt       *ytapi.Fake
+	tg       *tgbot.Fake
+	recorder *recorder.Fake
+
+	server *Server
+}
+
+// NewSimulation boots a Server with fakes on database dataSourceName.
+func NewSimulation(dataSourceName string) (*Simulation, error) {
+	db, err := newDatabase(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start on the hour, so regular updates are aligned with scheduled lives.
+	sim := &Simulation{
+		clock:    clock.NewFake(time.Now().Truncate(time.Hour)),
+		yt:       ytapi.NewFake(),
+		tg:       tgbot.NewFake(),
+		recorder: recorder.NewFake(simChatID),
+	}
+
+	sim.yt.Now = sim.clock.Now
+	sim.hub, sim.feedsCh = hub.NewFake()
+
+	setting := Setting{Host: "localhost"}
+	if err := setting.Validate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	sim.server = newServer(
+		setting, new(http.ServeMux),
+		sim.hub, sim.feedsCh,
+		sim.tg, nil,
+		sim.yt, db,
+		sim.clock,
+	)
+
+	return sim, nil
+}
+
+// Close shuts down fakes of simulation.
+func (sim *Simulation) Close() {
+	sim.recorder.Close()
+	sim.server.db.Close()
+}
+
+// send delivers a text message from chat as if it's sent to bot,
+// then waits until it's handled.
+func (sim *Simulation) send(chatID int64, text string) {
+	sim.server.dispatchUpdate(tgbot.Update{
+		Message: &tgbot.Message{
+			Chat: &tgbot.Chat{ID: chatID},
+			From: &tgbot.User{LanguageCode: "en"},
+			Text: text,
+			Date: int(sim.clock.Now().Unix()),
+		},
+	})
+	sim.clock.Wait()
+}
+
+// publish delivers feed through the hub, then waits until it's handled.
+func (sim *Simulation) publish(feed hub.Feed) {
+	sim.hub.Publish(feed)
+	sim.server.dispatchFeed(<-sim.feedsCh)
+	sim.clock.Wait()
+}
+
+// find returns the first record since index from of kind in chat which text contains substr.
+func (sim *Simulation) find(from int, kind tgbot.RecordKind, chatID int64, substr string) (tgbot.Record, bool) {
+	records := sim.tg.Records()
+	if from > len(records) {
+		return tgbot.Record{}, false
+	}
+
+	for _, r := range records[from:] {
+		if r.Kind == kind && r.ChatID == chatID && strings.Contains(r.Text, substr) {
+			return r, true
+		}
+	}
+
+	return tgbot.Record{}, false
+}
+
+func (sim *Simulation) recordRequested(videoID string) bool {
+	for _, req := range sim.recorder.Requests() {
+		if req["action"] == "record" && req["videoID"] == videoID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Run simulates the lifecycle of lives, from upcoming notice to deletion,
+// and returns error at the first unexpected step.
+func (sim *Simulation) Run() error {
+	log := logging.With("simulation", logging.NewID())
+
+	liveID, err := newSimVideoID()
+	if err != nil {
+		return err
+	}
+
+	deletedID, err := newSimVideoID()
+	if err != nil {
+		return err
+	}
+
+	sim.server.initScheduler()
+	sim.clock.Wait()
+
+	// Subscribe channel & setup recorder.
+	sim.yt.AddChannel(simChannelID, simChannelTitle, "@simulation")
+
+	sim.send(simChatID, "/add "+ytChannelURLPrefix+simChannelID)
+	if !sim.hub.IsSubscribed(simChannelID) {
+		return fmt.Errorf("simulation: channel is not subscribed")
+	}
+
+	if _, err := sim.server.db.Exec(
+		"UPDATE chats SET recorder = ?, token = ? WHERE id = ?;",
+		sim.recorder.Url, sim.recorder.Token, simChatID,
+	); err != nil {
+		return err
+	}
+	sim.server.getRecorders()
+
+	sim.send(simChatID, "~autorc "+ytChannelURLPrefix+simChannelID)
+	if exist, err := sim.server.isAutoRecorderExist(simChatID, simChannelID); err != nil {
+		return err
+	} else if !exist {
+		return fmt.Errorf("simulation: auto recorder is not set")
+	}
+
+	lc := sim.server.chatLocale(context.Background(), simChatID)
+
+	// 1. Upcoming notice.
+	start := sim.clock.Now().Add(2 * time.Hour)
+	sim.yt.AddVideo(simChannelID, liveID, "Simulated Live", start)
+	sim.yt.Script(liveID,
+		ytapi.Transition{At: start, State: ytapi.Live, Viewers: 1234},
+		ytapi.Transition{At: start.Add(time.Hour), State: ytapi.Completed},
+	)
+
+	sim.publish(hub.Feed{Entry: &hub.Entry{VideoID: liveID, ChannelID: simChannelID}})
+
+	notice, ok := sim.find(0, tgbot.Sent, simChatID, liveID)
+	if !ok {
+		return fmt.Errorf("simulation: upcoming notice is not sent")
+	}
+	log.Info("Simulation step passed", "step", "upcoming notice")
+
+	// 2. Reminder, the diligent scheduler edits the notice at checkpoints before start.
+	// Advance past the regular update, which starts the diligent scheduler.
+	sim.clock.Advance(time.Hour + 29*time.Minute)
+	from := len(sim.tg.Records())
+
+	sim.clock.Advance(30 * time.Minute)
+	if r, ok := sim.find(from, tgbot.Edited, simChatID, liveID); !ok || r.MessageID != notice.MessageID {
+		return fmt.Errorf("simulation: notice is not edited by diligent scheduler before start")
+	}
+	log.Info("Simulation step passed", "step", "reminder")
+
+	// 3. Live message.
+	sim.clock.Advance(2 * time.Minute)
+
+	nowLive := fmt.Sprintf(lc.text("notice.now_live"), tgbot.EscapeText(simChannelTitle))
+	if _, ok := sim.find(from, tgbot.Sent, simChatID, nowLive); !ok {
+		return fmt.Errorf("simulation: live message is not sent")
+	}
+	log.Info("Simulation step passed", "step", "live message")
+
+	// 4. Record request.
+	if !sim.recordRequested(liveID) {
+		return fmt.Errorf("simulation: record request is not sent, got %v", sim.recorder.Requests())
+	}
+	log.Info("Simulation step passed", "step", "record request")
+
+	// 5. Completion edit, by live tracker or regular update.
+	sim.clock.Advance(time.Hour + maxTrackInterval)
+
+	r, ok := sim.tg.Message(simChatID, notice.MessageID)
+	if !ok || r.Kind != tgbot.Edited || !strings.Contains(r.Text, lc.text("notice.completed")) {
+		return fmt.Errorf("simulation: notice = %+v, %v, want completed", r, ok)
+	}
+	log.Info("Simulation step passed", "step", "completion edit")
+
+	// 6. Deletion of an upcoming live.
+	sim.yt.AddVideo(simChannelID, deletedID, "Simulated Deleted Live", sim.clock.Now().Add(24*time.Hour))
+	sim.publish(hub.Feed{Entry: &hub.Entry{VideoID: deletedID, ChannelID: simChannelID}})
+
+	deleted, ok := sim.find(0, tgbot.Sent, simChatID, deletedID)
+	if !ok {
+		return fmt.Errorf("simulation: notice of live to delete is not sent")
+	}
+
+	sim.yt.SetState(deletedID, ytapi.Deleted, 0)
+	sim.publish(hub.Feed{DeletedEntry: &hub.DeletedEntry{Ref: "yt:video:" + deletedID}})
+
+	if _, ok := sim.tg.Message(simChatID, deleted.MessageID); ok {
+		return fmt.Errorf("simulation: notice of deleted live is not deleted")
+	}
+	log.Info("Simulation step passed", "step", "deletion")
+
+	return nil
+}
+
+// newSimVideoID returns a random video ID, so simulations won't collide in database.
+func newSimVideoID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)[:11], nil
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/chart"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+)
+
+// vodFollowUpLimit is how long we keep waiting for the VOD of a completed live.
+const vodFollowUpLimit = 7 * 24 * time.Hour
+
+// updatePeakViewers samples concurrent viewers of a live live and keeps the peak.
+func (s *Server) updatePeakViewers(ctx context.Context, video *ytapi.Video) {
+	log := logging.FromContext(ctx)
+
+	if !ytapi.IsLiveLiveBroadcast(video) || video.LiveStreamingDetails.ConcurrentViewers == 0 {
+		return
+	}
+
+	if _, err := s.db.Exec(
+		"INSERT INTO streamStats (videoID, peakViewers) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE peakViewers = GREATEST(peakViewers, VALUES(peakViewers));",
+		video.Id, video.LiveStreamingDetails.ConcurrentViewers,
+	); err != nil {
+		log.Error("Database error", "error", err)
+	}
+}
+
+// sendSummaries replies a stream ended summary to every notice of the completed live.
+// It returns chats whose summaries failed to be sent by network errors,
+// which should be retried later.
+func (s *Server) sendSummaries(ctx context.Context, video *ytapi.Video) (map[int64]bool, error) {
+	log := logging.FromContext(ctx)
+
+	notices, err := s.db.getNoticesByVideoID(video.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request final statistics of the live.
+	v, err := s.yt.GetVideo(video.Id, []string{"snippet", "liveStreamingDetails", "statistics"})
+	if err != nil {
+		log.Warning("YouTube API error", "error", err)
+		v = video
+	}
+
+	var peak sql.NullInt64
+	err = s.db.QueryRow("SELECT peakViewers FROM streamStats WHERE videoID = ?;", v.Id).Scan(&peak)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error("Database error", "error", err)
+	}
+
+	end, _ := time.Parse(time.RFC3339, v.LiveStreamingDetails.ActualEndTime)
+
+	// Render viewers chart if there are enough samples.
+	img, err := s.renderViewersChart(v.Id)
+	if err != nil {
+		log.Warning("Failed to render viewers chart", "error", err)
+	}
+
+	// Uploaded chart file id, reused by following chats.
+	var photoID string
+
+	retry := make(map[int64]bool)
+
+	for _, n := range notices {
+		if n.messageID == -1 {
+			// This chat has never been notified.
+			continue
+		}
+
+		// Make sure every chat only receives the summary once.
+		result, err := s.db.Exec(
+			"INSERT IGNORE INTO summaries (videoID, chatID, messageID, endTime) VALUES (?, ?, ?, ?);",
+			v.Id, n.chatID, -1, end.Unix(),
+		)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			retry[n.chatID] = true
+			continue
+		} else if affected, _ := result.RowsAffected(); affected == 0 {
+			continue
+		}
+
+		text := newSummaryMessageText(v, peak.Int64, s.chatLocale(ctx, n.chatID))
+
+		newConfig := func(replyTo int) tgbot.Chattable {
+			if img != nil {
+				var photoConfig tgbot.PhotoConfig
+				if photoID != "" {
+					photoConfig = tgbot.NewPhotoShare(n.chatID, photoID, text)
+				} else {
+					photoConfig = tgbot.NewPhotoUpload(n.chatID, tgbot.FileBytes{Name: v.Id + ".png", Bytes: img}, text)
+				}
+				photoConfig.ReplyToMessageID = replyTo
+				photoConfig.DisableNotification = true
+				return photoConfig
+			}
+
+			msgConfig := tgbot.NewMessage(n.chatID, text)
+			msgConfig.ReplyToMessageID = replyTo
+			msgConfig.DisableNotification = true
+			msgConfig.DisableWebPagePreview = true
+			return msgConfig
+		}
+
+		message, err := s.tgSend(ctx, newConfig(n.messageID))
+		if _, ok := err.(tgbot.Error); ok {
+			// The notice may be deleted, send it once more without reply.
+			message, err = s.tgSend(ctx, newConfig(0))
+		}
+
+		if err != nil {
+			// Drop the reservation, so no VOD follow-up is sent without summary.
+			if _, err := s.db.Exec(
+				"DELETE FROM summaries WHERE videoID = ? AND chatID = ? AND messageID = ?;",
+				v.Id, n.chatID, -1,
+			); err != nil {
+				log.Error("Database error", "error", err)
+			}
+
+			// Retry later on network errors, unless Telegram rejected it.
+			if _, ok := err.(tgbot.Error); !ok {
+				retry[n.chatID] = true
+			}
+			continue
+		} else if photoID == "" && message.Photo != nil && len(*message.Photo) != 0 {
+			photos := *message.Photo
+			photoID = photos[len(photos)-1].FileID
+		}
+
+		if _, err := s.db.Exec(
+			"UPDATE summaries SET messageID = ? WHERE videoID = ? AND chatID = ?;",
+			message.MessageID, v.Id, n.chatID,
+		); err != nil {
+			log.Error("Database error", "error", err)
+		}
+	}
+
+	return retry, nil
+}
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+)
+
+// renderViewersChart renders viewers over time of a video as PNG.
+// Returns nil if there are not enough samples.
+func (s *Server) renderViewersChart(videoID string) ([]byte, error) {
+	samples, err := s.db.getViewerSamples(videoID)
+	if err != nil {
+		return nil, err
+	} else if len(samples) < 2 {
+		return nil, nil
+	}
+
+	points := make([]chart.Point, len(samples))
+	for i, sp := range samples {
+		points[i] = chart.Point{X: float64(sp.Time), Y: float64(sp.Count)}
+	}
+
+	return chart.LineChart(points, chartWidth, chartHeight)
+}
+
+func newSummaryMessageText(video *ytapi.Video, peakViewers int64, lc locale) string {
+	details := video.LiveStreamingDetails
+
+	start, _ := time.Parse(time.RFC3339, details.ActualStartTime)
+	end, _ := time.Parse(time.RFC3339, details.ActualEndTime)
+
+	na := lc.raw("common.na")
+	var peak, views, likes string = na, na, na
+
+	if peakViewers > 0 {
+		peak = fmt.Sprint(peakViewers)
+	}
+
+	if video.Statistics != nil {
+		views = fmt.Sprint(video.Statistics.ViewCount)
+		likes = fmt.Sprint(video.Statistics.LikeCount)
+	}
+
+	return fmt.Sprintf(
+		"%s\n%s\n%s\n\n%s\n%s\n\n%s\n%s\n\n%s\n%s\n\n%s\n%s",
+		tgbot.BordText(lc.text("summary.title")),
+		tgbot.InlineLink(
+			tgbot.BordText(tgbot.EscapeText(video.Snippet.Title)),
+			ytVideoURLPrefix+video.Id,
+		),
+		tgbot.ItalicText(tgbot.EscapeText(video.Snippet.ChannelTitle)),
+		tgbot.BordText(lc.text("notice.duration")),
+		tgbot.ItalicText(formatDuration(end.Sub(start))),
+		tgbot.BordText(lc.text("summary.peak")),
+		tgbot.ItalicText(tgbot.EscapeText(peak)),
+		tgbot.BordText(lc.text("summary.views")),
+		tgbot.ItalicText(tgbot.EscapeText(views)),
+		tgbot.BordText(lc.text("summary.likes")),
+		tgbot.ItalicText(tgbot.EscapeText(likes)),
+	)
+}
+
+// updateSummaries follows up summaries when the VOD becomes available or is privated.
+func (s *Server) updateSummaries() {
+	ctx := logging.WithCorrelationID(context.Background(), logging.NewID())
+	ctx = logging.NewContext(ctx, "scheduler", "summary")
+	log := logging.FromContext(ctx)
+
+	var summaries []Summary
+
+	err := s.db.queryResults(
+		&summaries,
+		func(rows *sql.Rows, dest interface{}) error {
+			r := dest.(*Summary)
+			return rows.Scan(&r.videoID, &r.chatID, &r.messageID, &r.endTime, &r.title)
+		},
+		"SELECT summaries.videoID, summaries.chatID, summaries.messageID, summaries.endTime, "+
+			"COALESCE(videos.title, summaries.videoID) "+
+			"FROM summaries LEFT JOIN videos ON summaries.videoID = videos.id;",
+	)
+
+	if err != nil {
+		log.Error("Database error", "error", err)
+		return
+	} else if len(summaries) == 0 {
+		return
+	}
+
+	// Group summaries by video id.
+	var videoIDs []string
+	table := make(map[string][]Summary)
+
+	for _, sm := range summaries {
+		if _, ok := table[sm.videoID]; !ok {
+			videoIDs = append(videoIDs, sm.videoID)
+		}
+		table[sm.videoID] = append(table[sm.videoID], sm)
+	}
+
+	// Request video resources from yt api.
+	// Privated or removed videos will not be listed.
+	videos, err := s.yt.GetVideos(videoIDs, []string{"status", "contentDetails"})
+	if err != nil {
+		log.Warning("YouTube API error", "error", err)
+		return
+	}
+
+	found := make(map[string]*ytapi.Video)
+	for _, v := range videos {
+		found[v.Id] = v
+	}
+
+	for _, id := range videoIDs {
+		var msgKey string
+
+		v, ok := found[id]
+		if !ok || ytapi.IsPrivateVideo(v) {
+			msgKey = "vod.unavailable"
+		} else if ytapi.IsProcessedVideo(v) {
+			msgKey = "vod.available"
+		}
+
+		for _, sm := range table[id] {
+			if msgKey == "" {
+				// Still processing, give up if waiting too long.
+				if s.clock.Now().Sub(time.Unix(sm.endTime, 0)) < vodFollowUpLimit {
+					continue
+				}
+			} else {
+				msgConfig := tgbot.NewMessage(sm.chatID, fmt.Sprintf(
+					s.chatLocale(ctx, sm.chatID).text(msgKey),
+					tgbot.InlineLink(tgbot.EscapeText(sm.title), ytVideoURLPrefix+sm.videoID),
+				))
+				if sm.messageID != -1 {
+					msgConfig.ReplyToMessageID = sm.messageID
+				}
+				msgConfig.DisableNotification = true
+				msgConfig.DisableWebPagePreview = true
+
+				s.tgSend(ctx, msgConfig)
+			}
+
+			if _, err := s.db.Exec(
+				"DELETE FROM summaries WHERE videoID = ? AND chatID = ?;",
+				sm.videoID, sm.chatID,
+			); err != nil {
+				log.Error("Database error", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// Telegram message text & media caption length limits.
+const (
+	maxMessageLength = 4096
+	maxCaptionLength = 1024
+)
+
+// noticeFields are the fields available in notification message templates.
+// All values are plain text, they will be escaped when rendering.
+type noticeFields struct {
+	Title      string
+	URL        string
+	Channel    string
+	ChannelURL string
+	Thumbnail  string
+
+	// Restrictions are localized tags of a restricted video, e.g. members only.
+	MembersOnly      bool
+	AgeRestricted    bool
+	RegionRestricted bool
+	Restrictions     string
+
+	// Status is the localized live status.
+	Status    string
+	Upcoming  bool
+	Live      bool
+	Completed bool
+
+	// TimeTitle & Time are the most relevant time of current status.
+	TimeTitle      string
+	Time           string
+	ScheduledStart string
+	ActualStart    string
+	ActualEnd      string
+	Duration       string
+	Viewers        uint64
+}
+
+func newNoticeFields(video *ytapi.Video, lc locale) noticeFields {
+	f := noticeFields{
+		Title:      video.Snippet.Title,
+		URL:        ytVideoURLPrefix + video.Id,
+		Channel:    video.Snippet.ChannelTitle,
+		ChannelURL: ytChannelURLPrefix + video.Snippet.ChannelId,
+		Thumbnail:  ytapi.BestThumbnail(video),
+
+		MembersOnly:      ytapi.IsMembersOnly(video),
+		AgeRestricted:    ytapi.IsAgeRestricted(video),
+		RegionRestricted: ytapi.IsRegionRestricted(video),
+	}
+
+	var tags []string
+	if f.MembersOnly {
+		tags = append(tags, lc.raw("notice.members_only"))
+	}
+	if f.AgeRestricted {
+		tags = append(tags, lc.raw("notice.age_restricted"))
+	}
+	if f.RegionRestricted {
+		tags = append(tags, lc.raw("notice.region_restricted"))
+	}
+	f.Restrictions = strings.Join(tags, ", ")
+
+	details := video.LiveStreamingDetails
+	if details == nil {
+		return f
+	}
+
+	var scheduled, start, end time.Time
+	if details.ScheduledStartTime != "" {
+		scheduled, _ = time.Parse(time.RFC3339, details.ScheduledStartTime)
+		f.ScheduledStart = lc.timeWithRelative(scheduled)
+	}
+	if details.ActualStartTime != "" {
+		start, _ = time.Parse(time.RFC3339, details.ActualStartTime)
+		f.ActualStart = lc.timeWithRelative(start)
+	}
+	if details.ActualEndTime != "" {
+		end, _ = time.Parse(time.RFC3339, details.ActualEndTime)
+		f.ActualEnd = formatTime(end, lc.loc)
+	}
+
+	if details.ActualEndTime != "" {
+		// It's a completed live.
+		f.Completed = true
+		f.Status = lc.raw("notice.completed")
+		f.TimeTitle = lc.raw("notice.actual_end")
+		f.Time = f.ActualEnd
+		f.Duration = formatDuration(end.Sub(start))
+	} else if details.ActualStartTime != "" {
+		// It's a live live.
+		f.Live = true
+		f.Status = lc.raw("notice.live")
+		f.TimeTitle = lc.raw("notice.actual_start")
+		f.Time = f.ActualStart
+		f.Viewers = details.ConcurrentViewers
+	} else if details.ScheduledStartTime != "" {
+		// It's a upcoming live.
+		f.Upcoming = true
+		f.Status = lc.raw("notice.upcoming")
+		f.TimeTitle = lc.raw("notice.scheduled_start")
+		f.Time = f.ScheduledStart
+	}
+
+	return f
+}
+
+// noticeFieldNames lists field names shown to users.
+var noticeFieldNames = []string{
+	"Title", "URL", "Channel", "ChannelURL", "Thumbnail",
+	"MembersOnly", "AgeRestricted", "RegionRestricted", "Restrictions",
+	"Status", "Upcoming", "Live", "Completed",
+	"TimeTitle", "Time", "ScheduledStart", "ActualStart", "ActualEnd",
+	"Duration", "Viewers",
+}
+
+// markup is a text which is already formatted as MarkdownV2.
+type markup string
+
+var templateFuncs = template.FuncMap{
+	"escape": escapeTemplateValue,
+	"bold": func(v interface{}) markup {
+		return markup(tgbot.BordText(string(escapeTemplateValue(v))))
+	},
+	"italic": func(v interface{}) markup {
+		return markup(tgbot.ItalicText(string(escapeTemplateValue(v))))
+	},
+	"code": func(v interface{}) markup {
+		return markup(tgbot.InlineCode(string(escapeTemplateValue(v))))
+	},
+	"link": func(v interface{}, url string) markup {
+		url = strings.NewReplacer(`\`, `\\`, `)`, `\)`).Replace(url)
+		return markup(tgbot.InlineLink(string(escapeTemplateValue(v)), url))
+	},
+}
+
+// escapeTemplateValue escapes any value except markup.
+func escapeTemplateValue(v interface{}) markup {
+	if m, ok := v.(markup); ok {
+		return m
+	}
+	return markup(tgbot.EscapeText(fmt.Sprint(v)))
+}
+
+// parseNoticeTemplate parses a notification message template.
+// Literal texts & action outputs are escaped automatically, formatting
+// should be done by template functions.
+func parseNoticeTemplate(content string) (*template.Template, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("empty template")
+	}
+
+	t, err := template.New("notice").Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return nil, err
+	} else if len(t.Templates()) > 1 {
+		return nil, errors.New("defining templates is not allowed")
+	}
+
+	if err := escapeTemplateNode(t.Tree.Root); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// escapeTemplateNode walks through the parse tree, escapes literal texts
+// and pipes every action output into `escape`.
+func escapeTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := escapeTemplateNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode:
+		n.Text = []byte(tgbot.EscapeText(string(n.Text)))
+	case *parse.ActionNode:
+		// Declarations have no output.
+		if len(n.Pipe.Decl) == 0 {
+			cmd := &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("escape").SetPos(n.Pos)},
+			}
+			n.Pipe.Cmds = append(n.Pipe.Cmds, cmd)
+		}
+	case *parse.IfNode:
+		return escapeBranchNode(&n.BranchNode)
+	case *parse.RangeNode:
+		// Ranging over literals or function results may loop without bound.
+		if !isFieldPipe(n.Pipe) {
+			return errors.New("range over non-field is not allowed")
+		}
+		return escapeBranchNode(&n.BranchNode)
+	case *parse.WithNode:
+		return escapeBranchNode(&n.BranchNode)
+	case *parse.TemplateNode:
+		return errors.New("invoking templates is not allowed")
+	}
+
+	return nil
+}
+
+func escapeBranchNode(n *parse.BranchNode) error {
+	if err := escapeTemplateNode(n.List); err != nil {
+		return err
+	}
+	return escapeTemplateNode(n.ElseList)
+}
+
+// isFieldPipe reports whether pipe is a single field, e.g. `.Title`.
+func isFieldPipe(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+
+	_, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	return ok
+}
+
+// limitedWriter fails once more than n bytes are written,
+// so executing a template can't fill memory.
+type limitedWriter struct {
+	buf bytes.Buffer
+	n   int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.n {
+		return 0, fmt.Errorf("message longer than %d characters", maxMessageLength)
+	}
+	return w.buf.Write(p)
+}
+
+// renderNoticeTemplate renders notification message text by template content.
+func renderNoticeTemplate(content string, fields noticeFields) (string, error) {
+	t, err := parseNoticeTemplate(content)
+	if err != nil {
+		return "", err
+	}
+
+	// Every character takes at most utf8.UTFMax bytes.
+	w := &limitedWriter{n: maxMessageLength * utf8.UTFMax}
+	if err := t.Execute(w, fields); err != nil {
+		return "", err
+	}
+
+	text := w.buf.String()
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("empty message")
+	} else if len([]rune(text)) > maxMessageLength {
+		return "", fmt.Errorf("message longer than %d characters", maxMessageLength)
+	}
+
+	return text, nil
+}
+
+// chatNotifyMessageText renders notification message text by chat template,
+// falls back to default layout if not set, failed or too long with label.
+// Label is prepended if not empty, e.g. featuring guest channels.
+func (s *Server) chatNotifyMessageText(ctx context.Context, chatID int64, video *ytapi.Video, label string, lc locale) string {
+	log := logging.FromContext(ctx)
+
+	content, err := s.db.getChatTemplate(chatID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+	} else if content != "" {
+		text, err := renderNoticeTemplate(content, newNoticeFields(video, lc))
+		if err == nil {
+			text = withLabel(label, text)
+			if len([]rune(text)) <= maxMessageLength {
+				return text
+			}
+			err = fmt.Errorf("message longer than %d characters", maxMessageLength)
+		}
+		log.Warning("Failed to render template", "chatID", chatID, "error", err)
+	}
+
+	return withLabel(label, newNotifyMessageText(video, lc))
+}
+
+// noticeCaption returns text if it fits in a photo caption,
+// or default layout with label, which is dropped if still too long.
+func noticeCaption(text, label string, video *ytapi.Video, lc locale) string {
+	if len([]rune(text)) <= maxCaptionLength {
+		return text
+	}
+
+	if caption := withLabel(label, newNotifyMessageText(video, lc)); len([]rune(caption)) <= maxCaptionLength {
+		return caption
+	}
+
+	return newNotifyMessageText(video, lc)
+}
+
+func withLabel(label, text string) string {
+	if label == "" {
+		return text
+	}
+
+	return label + "\n" + text
+}
+
+// sampleNoticeVideo is used to preview templates, which starts a while after now.
+func sampleNoticeVideo(now time.Time) *ytapi.Video {
+	return &ytapi.Video{
+		Id: "dQw4w9WgXcQ",
+		Snippet: &youtube.VideoSnippet{
+			Title:        "Sample Live Stream",
+			ChannelId:    "UC38IQsAvIsxxjztdMZQtwHA",
+			ChannelTitle: "Sample Channel",
+		},
+		LiveStreamingDetails: &youtube.VideoLiveStreamingDetails{
+			ScheduledStartTime: now.Add(2*time.Hour + 15*time.Minute).Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderNoticeTemplateLimits(t *testing.T) {
+	fields := noticeFields{Title: "Title", Viewers: 100000}
+
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"{{.Title}}", ""},
+		{"{{range 1000000000}}x{{end}}", "range over non-field"},
+		{"{{range len .Title}}{{end}}", "range over non-field"},
+		{"{{if .Live}}{{else}}{{range 5}}{{end}}{{end}}", "range over non-field"},
+		{"{{range .Viewers}}x{{end}}", "message longer than"},
+		{"{{range $i := .Viewers}}{{$i}}{{end}}", "message longer than"},
+	}
+
+	for _, tt := range tests {
+		_, err := renderNoticeTemplate(tt.content, fields)
+
+		if tt.err == "" && err != nil {
+			t.Errorf("renderNoticeTemplate(%q) error = %v", tt.content, err)
+		} else if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("renderNoticeTemplate(%q) error = %v, want %q", tt.content, err, tt.err)
+		}
+	}
+}
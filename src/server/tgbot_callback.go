@@ -1,13 +1,15 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/filter"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
-	"github.com/golang/glog"
 )
 
 type CallbackDataType int
@@ -18,6 +20,8 @@ const (
 	Operation
 	Filter
 	Remove
+	Schedule
+	Subscribe
 )
 
 type OperationType int
@@ -26,11 +30,13 @@ const (
 	FilterOp OperationType = iota
 	RemoveOp
 	BackOp
+	TestFilterOp
 )
 
-func (s *Server) callbackHandler(update tgbot.Update) {
+func (s *Server) callbackHandler(ctx context.Context, update tgbot.Update) {
 	// Basic callback info
 	callbackID := update.CallbackQuery.ID
+	log := logging.FromContext(ctx).With("callbackID", callbackID)
 
 	// Decode callback data
 	data := make(map[string]interface{})
@@ -40,42 +46,46 @@ func (s *Server) callbackHandler(update tgbot.Update) {
 
 	switch CallbackDataType(data["type"].(float64)) {
 	case Record:
-		err = s.callbackRecordHandler(update)
+		err = s.callbackRecordHandler(ctx, update)
 	case List:
-		err = s.callbackListHandler(update)
+		err = s.callbackListHandler(ctx, update)
 	case Operation:
-		err = s.callbackOpHandler(update)
+		err = s.callbackOpHandler(ctx, update)
 	case Filter:
-		err = s.callbackFilterHandler(update)
+		err = s.callbackFilterHandler(ctx, update)
 	case Remove:
-		err = s.callbackRemoveHandler(update)
+		err = s.callbackRemoveHandler(ctx, update)
+	case Schedule:
+		err = s.callbackScheduleHandler(ctx, update)
+	case Subscribe:
+		err = s.callbackSubscribeHandler(ctx, update)
 	default:
 		err = fmt.Errorf("invalid callback type: %v", data["type"])
 	}
 
 	if err != nil {
-		s.internalServerErrorCallback(callbackID)
-		glog.Error(err)
+		s.internalServerErrorCallback(ctx, callbackID, s.chatLocale(ctx, update.CallbackQuery.Message.Chat.ID))
+		log.Error("Failed to handle callback", "error", err, "data", update.CallbackQuery.Data)
 		return
 	}
 
 	s.tg.AnswerCallbackQuery(tgbot.CallbackConfig{CallbackQueryID: callbackID})
 }
 
-func (s *Server) newRecordButtonMarkup(videoID string) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newRecordButtonMarkup(videoID string, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	data := make(map[string]interface{})
 	data["type"] = Record
 	data["videoID"] = videoID
 	b, _ := json.Marshal(data)
 
-	button := tgbot.NewInlineKeyboardButtonData("Record", string(b))
+	button := tgbot.NewInlineKeyboardButtonData(lc.raw("button.record"), string(b))
 	row := tgbot.NewInlineKeyboardRow(button)
 	markup := tgbot.NewInlineKeyboardMarkup(row)
 
 	return &markup, nil
 }
 
-func (s *Server) callbackRecordHandler(update tgbot.Update) error {
+func (s *Server) callbackRecordHandler(ctx context.Context, update tgbot.Update) error {
 	// Basic callback info
 	callbackID := update.CallbackQuery.ID
 	chatID := update.CallbackQuery.Message.Chat.ID
@@ -88,6 +98,8 @@ func (s *Server) callbackRecordHandler(update tgbot.Update) error {
 
 	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
 
+	lc := s.chatLocale(ctx, chatID)
+
 	if _, err := s.db.Exec(
 		"INSERT IGNORE INTO records (chatID, videoID) VALUES (?, ?);",
 		chatID, data.VideoID,
@@ -95,16 +107,16 @@ func (s *Server) callbackRecordHandler(update tgbot.Update) error {
 		return err
 	}
 
-	callback := tgbot.NewCallback(callbackID, fmt.Sprintf("Add %s recorder", data.VideoID))
+	callback := tgbot.NewCallback(callbackID, fmt.Sprintf(lc.raw("record.added"), data.VideoID))
 	s.tg.AnswerCallbackQuery(callback)
 
 	cfg := tgbot.NewEditMessageReplyMarkup(chatID, msgID, tgbot.InlineKeyboardMarkup{InlineKeyboard: [][]tgbot.InlineKeyboardButton{{}}})
-	s.tgSend(cfg)
+	s.tgSend(ctx, cfg)
 
 	return nil
 }
 
-func (s *Server) newChannelListMarkUp(chatID int64, page int) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newChannelListMarkUp(chatID int64, page int, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	const MAX_LIST_LENGTH = 5
 
 	channels, err := s.db.getChannelsByChatID(chatID)
@@ -157,13 +169,23 @@ func (s *Server) newChannelListMarkUp(chatID int64, page int) (*tgbot.InlineKeyb
 			rows = append(rows, row)
 		}
 
+		// Construct `chat-wide filters` button
+		data := make(map[string]interface{})
+		data["type"] = Operation
+		data["cid"] = globalFilterChannel
+		data["op"] = FilterOp
+		data["page"] = page
+		b, _ := json.Marshal(data)
+		button := tgbot.NewInlineKeyboardButtonData(lc.raw("button.global_filter"), string(b))
+		rows = append(rows, tgbot.NewInlineKeyboardRow(button))
+
 		markup := tgbot.NewInlineKeyboardMarkup(rows...)
 
 		return &markup, nil
 	}
 }
 
-func (s *Server) callbackListHandler(update tgbot.Update) error {
+func (s *Server) callbackListHandler(ctx context.Context, update tgbot.Update) error {
 	// Basic callback info
 	chatID := update.CallbackQuery.Message.Chat.ID
 	msgID := update.CallbackQuery.Message.MessageID
@@ -176,18 +198,20 @@ func (s *Server) callbackListHandler(update tgbot.Update) error {
 
 	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
 
+	lc := s.chatLocale(ctx, chatID)
+
 	if data.ChannelID == "" {
 		// Turn page
-		markup, err := s.newChannelListMarkUp(chatID, data.Page)
+		markup, err := s.newChannelListMarkUp(chatID, data.Page, lc)
 		if err != nil {
 			return err
 		}
 
 		cfg := tgbot.NewEditMessageReplyMarkup(chatID, msgID, *markup)
-		s.tgSend(cfg)
+		s.tgSend(ctx, cfg)
 	} else {
 		// Subscribed channel operation
-		markup, err := s.newChannelOpMarkUp(data.ChannelID, data.Page)
+		markup, err := s.newChannelOpMarkUp(data.ChannelID, data.Page, lc)
 		if err != nil {
 			return err
 		}
@@ -198,15 +222,15 @@ func (s *Server) callbackListHandler(update tgbot.Update) error {
 			return err
 		}
 
-		link := tgbot.InlineLink(tgbot.EscapeText(title), "https://www.youtube.com/channel/"+data.ChannelID)
-		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, fmt.Sprintf("Here it is: %s\nWhat do you want to do with the channel?", link), *markup)
-		s.tgSend(cfg)
+		link := tgbot.InlineLink(tgbot.EscapeText(title), ytChannelURLPrefix+data.ChannelID)
+		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, fmt.Sprintf(lc.text("list.operation"), link), *markup)
+		s.tgSend(ctx, cfg)
 	}
 
 	return nil
 }
 
-func (s *Server) newChannelOpMarkUp(channelID string, page int) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newChannelOpMarkUp(channelID string, page int, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	var data map[string]interface{}
 	var b []byte
 	var buttons [][]tgbot.InlineKeyboardButton
@@ -219,7 +243,7 @@ func (s *Server) newChannelOpMarkUp(channelID string, page int) (*tgbot.InlineKe
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	filter := tgbot.NewInlineKeyboardButtonData("Filter", string(b))
+	filter := tgbot.NewInlineKeyboardButtonData(lc.raw("button.filter"), string(b))
 	buttons = append(buttons, tgbot.NewInlineKeyboardRow(filter))
 
 	// Construct `remove` button
@@ -230,7 +254,7 @@ func (s *Server) newChannelOpMarkUp(channelID string, page int) (*tgbot.InlineKe
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	remove := tgbot.NewInlineKeyboardButtonData("Remove", string(b))
+	remove := tgbot.NewInlineKeyboardButtonData(lc.raw("button.remove"), string(b))
 	buttons = append(buttons, tgbot.NewInlineKeyboardRow(remove))
 
 	// Construct `back` button
@@ -240,7 +264,7 @@ func (s *Server) newChannelOpMarkUp(channelID string, page int) (*tgbot.InlineKe
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	back := tgbot.NewInlineKeyboardButtonData("« Back to Channel List", string(b))
+	back := tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_channel_list"), string(b))
 	buttons = append(buttons, tgbot.NewInlineKeyboardRow(back))
 
 	markup := tgbot.NewInlineKeyboardMarkup(buttons...)
@@ -248,7 +272,7 @@ func (s *Server) newChannelOpMarkUp(channelID string, page int) (*tgbot.InlineKe
 	return &markup, nil
 }
 
-func (s *Server) callbackOpHandler(update tgbot.Update) error {
+func (s *Server) callbackOpHandler(ctx context.Context, update tgbot.Update) error {
 	// Basic callback info
 	chatID := update.CallbackQuery.Message.Chat.ID
 	msgID := update.CallbackQuery.Message.MessageID
@@ -262,56 +286,48 @@ func (s *Server) callbackOpHandler(update tgbot.Update) error {
 
 	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
 
+	lc := s.chatLocale(ctx, chatID)
+
 	switch data.Op {
 	case FilterOp:
-		markup, err := s.newChannelFilterMarkUp(data.ChannelID, data.Page)
+		markup, err := s.newChannelFilterMarkUp(data.ChannelID, data.Page, lc)
 		if err != nil {
 			return err
 		}
 
-		// Get channel title
-		title, err := s.db.getChannelTitle(data.ChannelID)
+		heading, err := s.filterHeading(data.ChannelID, lc)
 		if err != nil {
 			return err
 		}
 
-		link := tgbot.InlineLink(tgbot.EscapeText(title), "https://www.youtube.com/channel/"+data.ChannelID)
-
-		type Filter struct {
-			block   bool
-			content string
-		}
-
-		var filters []Filter
-
-		if err := s.db.queryResults(
-			&filters,
-			func(rows *sql.Rows, dest interface{}) error {
-				filter := dest.(*Filter)
-				return rows.Scan(&filter.block, &filter.content)
-			},
-			"SELECT block, content FROM filters WHERE chatID = ? AND channelID = ?;",
-			chatID, data.ChannelID,
-		); err != nil {
+		black, white, err := s.db.getFilterLists(chatID, data.ChannelID)
+		if err != nil {
 			return err
 		}
 
-		var black, white string
-		for _, v := range filters {
-			if v.block {
-				black = v.content
-			} else {
-				white = v.content
-			}
+		expr, err := s.db.getFilterExpr(chatID, data.ChannelID)
+		if err != nil {
+			return err
 		}
 
 		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, fmt.Sprintf(
-			"Setup notify filter: %s\n\n_blacklist:_\n%s\n\n_whitelist:_\n%s",
-			link, tgbot.EscapeText(black), tgbot.EscapeText(white),
+			"%s\n\n%s",
+			fmt.Sprintf(lc.text("filter.setup"), heading),
+			newFilterListsText(lc, black, white, expr),
 		), *markup)
-		s.tgSend(cfg)
+		s.tgSend(ctx, cfg)
+	case TestFilterOp:
+		text, err := s.newFilterTestText(ctx, chatID, data.ChannelID, lc)
+		if err != nil {
+			return err
+		}
+
+		cfg := tgbot.NewMessage(chatID, text)
+		cfg.DisableNotification = true
+		cfg.DisableWebPagePreview = true
+		s.tgSend(ctx, cfg)
 	case RemoveOp:
-		markup, err := s.newChannelRemoveMarkUp(data.ChannelID, data.Page)
+		markup, err := s.newChannelRemoveMarkUp(data.ChannelID, data.Page, lc)
 		if err != nil {
 			return err
 		}
@@ -322,23 +338,23 @@ func (s *Server) callbackOpHandler(update tgbot.Update) error {
 			return err
 		}
 
-		link := tgbot.InlineLink(tgbot.EscapeText(title), "https://www.youtube.com/channel/"+data.ChannelID)
-		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, fmt.Sprintf("Do you really want to remove %s?", link), *markup)
-		s.tgSend(cfg)
+		link := tgbot.InlineLink(tgbot.EscapeText(title), ytChannelURLPrefix+data.ChannelID)
+		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, fmt.Sprintf(lc.text("remove.confirm"), link), *markup)
+		s.tgSend(ctx, cfg)
 	case BackOp:
-		markup, err := s.newChannelListMarkUp(chatID, data.Page)
+		markup, err := s.newChannelListMarkUp(chatID, data.Page, lc)
 		if err != nil {
 			return err
 		}
 
-		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, "You already subscribed following channels:", *markup)
-		s.tgSend(cfg)
+		cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, lc.text("list.header"), *markup)
+		s.tgSend(ctx, cfg)
 	}
 
 	return nil
 }
 
-func (s *Server) newChannelFilterMarkUp(channelID string, page int) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newChannelFilterMarkUp(channelID string, page int, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	var data map[string]interface{}
 	var b []byte
 	var rows [][]tgbot.InlineKeyboardButton
@@ -351,7 +367,7 @@ func (s *Server) newChannelFilterMarkUp(channelID string, page int) (*tgbot.Inli
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	blacklist := tgbot.NewInlineKeyboardButtonData("blacklist", string(b))
+	blacklist := tgbot.NewInlineKeyboardButtonData(lc.raw("button.blacklist"), string(b))
 
 	// Construct `whitelist` button
 	data = make(map[string]interface{})
@@ -361,18 +377,52 @@ func (s *Server) newChannelFilterMarkUp(channelID string, page int) (*tgbot.Inli
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	whitelist := tgbot.NewInlineKeyboardButtonData("whitelist", string(b))
+	whitelist := tgbot.NewInlineKeyboardButtonData(lc.raw("button.whitelist"), string(b))
 
 	rows = append(rows, tgbot.NewInlineKeyboardRow(blacklist, whitelist))
 
-	// Construct `back` button
+	// Construct `expression` button
 	data = make(map[string]interface{})
-	data["type"] = List
+	data["type"] = Filter
 	data["cid"] = channelID
+	data["block"] = exprFilterBlock
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	back := tgbot.NewInlineKeyboardButtonData("« Back to Operation List", string(b))
+	expr := tgbot.NewInlineKeyboardButtonData(lc.raw("button.expression"), string(b))
+
+	// Construct `test` button
+	data = make(map[string]interface{})
+	data["type"] = Operation
+	data["cid"] = channelID
+	data["op"] = TestFilterOp
+	data["page"] = page
+
+	b, _ = json.Marshal(data)
+	test := tgbot.NewInlineKeyboardButtonData(lc.raw("button.test_filter"), string(b))
+
+	rows = append(rows, tgbot.NewInlineKeyboardRow(expr, test))
+
+	// Construct `back` button
+	var back tgbot.InlineKeyboardButton
+	if channelID == globalFilterChannel {
+		// Chat-wide filters have no channel operations.
+		data = make(map[string]interface{})
+		data["type"] = Operation
+		data["op"] = BackOp
+		data["page"] = page
+
+		b, _ = json.Marshal(data)
+		back = tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_channel_list"), string(b))
+	} else {
+		data = make(map[string]interface{})
+		data["type"] = List
+		data["cid"] = channelID
+		data["page"] = page
+
+		b, _ = json.Marshal(data)
+		back = tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_operation_list"), string(b))
+	}
 
 	rows = append(rows, tgbot.NewInlineKeyboardRow(back))
 
@@ -381,7 +431,7 @@ func (s *Server) newChannelFilterMarkUp(channelID string, page int) (*tgbot.Inli
 	return &markup, nil
 }
 
-func (s *Server) callbackFilterHandler(update tgbot.Update) error {
+func (s *Server) callbackFilterHandler(ctx context.Context, update tgbot.Update) error {
 	// Basic callback info
 	chatID := update.CallbackQuery.Message.Chat.ID
 
@@ -395,24 +445,35 @@ func (s *Server) callbackFilterHandler(update tgbot.Update) error {
 
 	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
 
-	title, err := s.db.getChannelTitle(data.ChannelID)
+	lc := s.chatLocale(ctx, chatID)
+
+	link, err := s.filterHeading(data.ChannelID, lc)
 	if err != nil {
 		return err
 	}
 
-	link := tgbot.InlineLink(tgbot.EscapeText(title), "https://www.youtube.com/channel/"+data.ChannelID)
-
-	var listname string
-	if data.Block != 0 {
-		listname = "blacklist"
+	var cfg tgbot.MessageConfig
+	if data.Block == exprFilterBlock {
+		cfg = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("filter.expr_prompt"),
+			link, tgbot.InlineCode(tgbot.EscapeText("--")),
+		))
 	} else {
-		listname = "whitelist"
-	}
+		var listname string
+		if data.Block != 0 {
+			listname = lc.text("button.blacklist")
+		} else {
+			listname = lc.text("button.whitelist")
+		}
 
-	cfg := tgbot.NewMessage(chatID, fmt.Sprintf("Setup notify %s: %s\nSeperated by comma, input `\\-\\-` to clear filter\\.", listname, link))
+		cfg = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("filter.list_prompt"),
+			listname, link, tgbot.InlineCode(tgbot.EscapeText("--")),
+		))
+	}
 	cfg.ReplyMarkup = tgbot.ForceReply{ForceReply: true}
 
-	msg, err := s.tgSend(cfg)
+	msg, err := s.tgSend(ctx, cfg)
 	if err != nil {
 		return nil
 	}
@@ -423,6 +484,24 @@ func (s *Server) callbackFilterHandler(update tgbot.Update) error {
 	return nil
 }
 
+// exprFilterBlock is the block value of filter expression in callback data,
+// besides blacklist (1) & whitelist (0).
+const exprFilterBlock = 2
+
+// filterHeading renders the target of filters, a channel link or chat-wide.
+func (s *Server) filterHeading(channelID string, lc locale) (string, error) {
+	if channelID == globalFilterChannel {
+		return tgbot.BordText(lc.text("filter.global")), nil
+	}
+
+	title, err := s.db.getChannelTitle(channelID)
+	if err != nil {
+		return "", err
+	}
+
+	return tgbot.InlineLink(tgbot.EscapeText(title), ytChannelURLPrefix+channelID), nil
+}
+
 var chatLatestPendingReplyData = make(map[int64]struct {
 	MessageID int
 	ChannelID string `json:"cid"`
@@ -430,13 +509,40 @@ var chatLatestPendingReplyData = make(map[int64]struct {
 	Page      int    `json:"page"`
 })
 
-func (s *Server) filterReplyHandler(update tgbot.Update) error {
+func (s *Server) filterReplyHandler(ctx context.Context, update tgbot.Update) error {
 	chatID := update.Message.Chat.ID
+	lc := s.chatLocale(ctx, chatID)
 
 	var cfg tgbot.MessageConfig
 
 	if data, ok := chatLatestPendingReplyData[chatID]; ok {
-		if update.Message.Text == "--" {
+		if data.Block == exprFilterBlock {
+			text := strings.TrimSpace(update.Message.Text)
+
+			if text == "--" {
+				// Clear expression
+				if _, err := s.db.Exec(
+					"DELETE FROM filterExprs WHERE chatID = ? AND channelID = ?;",
+					chatID, data.ChannelID,
+				); err != nil {
+					return err
+				}
+			} else if _, err := filter.Parse(text); err != nil {
+				// Keep waiting for a valid expression.
+				cfg = tgbot.NewMessage(chatID, fmt.Sprintf(
+					lc.text("filter.expr_invalid"),
+					tgbot.InlineCode(tgbot.EscapeText(err.Error())),
+				))
+				s.tgSend(ctx, cfg)
+				return nil
+			} else if _, err := s.db.Exec(
+				"INSERT INTO filterExprs (chatID, channelID, expr) VALUES (?, ?, ?) "+
+					"ON DUPLICATE KEY UPDATE expr = VALUES(expr);",
+				chatID, data.ChannelID, text,
+			); err != nil {
+				return err
+			}
+		} else if update.Message.Text == "--" {
 			// Clear filter
 			if _, err := s.db.Exec(
 				"INSERT INTO filters (chatID, channelID, block, content) VALUES(?, ?, ?, ?) "+
@@ -462,23 +568,23 @@ func (s *Server) filterReplyHandler(update tgbot.Update) error {
 			}
 		}
 
-		markup, err := s.newFilterReplyMarkUp(data.ChannelID, data.Page)
+		markup, err := s.newFilterReplyMarkUp(data.ChannelID, data.Page, lc)
 		if err != nil {
 			return err
 		}
 
-		cfg = tgbot.NewMessage(chatID, tgbot.EscapeText("Success! Filter updated."))
+		cfg = tgbot.NewMessage(chatID, lc.text("filter.updated"))
 		cfg.ReplyMarkup = markup
 	} else {
-		cfg = tgbot.NewMessage(chatID, tgbot.EscapeText("This message is too old."))
+		cfg = tgbot.NewMessage(chatID, lc.text("filter.reply_too_old"))
 	}
 
-	s.tgSend(cfg)
+	s.tgSend(ctx, cfg)
 
 	return nil
 }
 
-func (s *Server) newFilterReplyMarkUp(channelID string, page int) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newFilterReplyMarkUp(channelID string, page int, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	var data map[string]interface{}
 	var b []byte
 	var rows [][]tgbot.InlineKeyboardButton
@@ -490,7 +596,7 @@ func (s *Server) newFilterReplyMarkUp(channelID string, page int) (*tgbot.Inline
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	operation := tgbot.NewInlineKeyboardButtonData("« Back to Operation List", string(b))
+	operation := tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_operation_list"), string(b))
 
 	// Construct `back to filter operation` button
 	data = make(map[string]interface{})
@@ -500,9 +606,14 @@ func (s *Server) newFilterReplyMarkUp(channelID string, page int) (*tgbot.Inline
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	filter := tgbot.NewInlineKeyboardButtonData("« Back to Filter Operation", string(b))
+	filterOp := tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_filter_operation"), string(b))
 
-	rows = append(rows, tgbot.NewInlineKeyboardRow(operation, filter))
+	if channelID == globalFilterChannel {
+		// Chat-wide filters have no channel operations.
+		rows = append(rows, tgbot.NewInlineKeyboardRow(filterOp))
+	} else {
+		rows = append(rows, tgbot.NewInlineKeyboardRow(operation, filterOp))
+	}
 
 	// Construct `back to channel list` button
 	data = make(map[string]interface{})
@@ -511,7 +622,7 @@ func (s *Server) newFilterReplyMarkUp(channelID string, page int) (*tgbot.Inline
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	channelList := tgbot.NewInlineKeyboardButtonData("« Back to Channel List", string(b))
+	channelList := tgbot.NewInlineKeyboardButtonData(lc.raw("button.back_channel_list"), string(b))
 
 	rows = append(rows, tgbot.NewInlineKeyboardRow(channelList))
 	markup := tgbot.NewInlineKeyboardMarkup(rows...)
@@ -519,7 +630,7 @@ func (s *Server) newFilterReplyMarkUp(channelID string, page int) (*tgbot.Inline
 	return &markup, nil
 }
 
-func (s *Server) newChannelRemoveMarkUp(channelID string, page int) (*tgbot.InlineKeyboardMarkup, error) {
+func (s *Server) newChannelRemoveMarkUp(channelID string, page int, lc locale) (*tgbot.InlineKeyboardMarkup, error) {
 	var data map[string]interface{}
 	var b []byte
 
@@ -529,7 +640,7 @@ func (s *Server) newChannelRemoveMarkUp(channelID string, page int) (*tgbot.Inli
 	data["cid"] = channelID
 
 	b, _ = json.Marshal(data)
-	remove := tgbot.NewInlineKeyboardButtonData("Remove", string(b))
+	remove := tgbot.NewInlineKeyboardButtonData(lc.raw("button.remove"), string(b))
 
 	// Construct `cancel` button
 	data = make(map[string]interface{})
@@ -538,7 +649,7 @@ func (s *Server) newChannelRemoveMarkUp(channelID string, page int) (*tgbot.Inli
 	data["page"] = page
 
 	b, _ = json.Marshal(data)
-	cancel := tgbot.NewInlineKeyboardButtonData("Cancel", string(b))
+	cancel := tgbot.NewInlineKeyboardButtonData(lc.raw("button.cancel"), string(b))
 
 	row := tgbot.NewInlineKeyboardRow(remove, cancel)
 	markup := tgbot.NewInlineKeyboardMarkup(row)
@@ -546,7 +657,9 @@ func (s *Server) newChannelRemoveMarkUp(channelID string, page int) (*tgbot.Inli
 	return &markup, nil
 }
 
-func (s *Server) callbackRemoveHandler(update tgbot.Update) error {
+func (s *Server) callbackRemoveHandler(ctx context.Context, update tgbot.Update) error {
+	log := logging.FromContext(ctx)
+
 	// Basic callback info
 	chatID := update.CallbackQuery.Message.Chat.ID
 	msgID := update.CallbackQuery.Message.MessageID
@@ -558,6 +671,8 @@ func (s *Server) callbackRemoveHandler(update tgbot.Update) error {
 
 	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
 
+	lc := s.chatLocale(ctx, chatID)
+
 	// Get channel title
 	title, err := s.db.getChannelTitle(data.ChannelID)
 	if err != nil {
@@ -570,12 +685,12 @@ func (s *Server) callbackRemoveHandler(update tgbot.Update) error {
 		return err
 	}
 
-	link := tgbot.InlineLink(tgbot.EscapeText(title), "https://www.youtube.com/channel/"+data.ChannelID)
-	cfg := tgbot.NewEditMessageText(chatID, msgID, fmt.Sprintf("You have unsubscribed\n%s", link))
-	s.tgSend(cfg)
+	link := tgbot.InlineLink(tgbot.EscapeText(title), ytChannelURLPrefix+data.ChannelID)
+	cfg := tgbot.NewEditMessageText(chatID, msgID, fmt.Sprintf(lc.text("remove.done"), link))
+	s.tgSend(ctx, cfg)
 
 	// Check not subscribed channels & unsubscribe them from hub
-	go func() {
+	s.clock.Go(func() {
 		var channelIDs []string
 
 		err := s.db.queryResults(
@@ -590,19 +705,74 @@ func (s *Server) callbackRemoveHandler(update tgbot.Update) error {
 		)
 
 		if err != nil {
-			glog.Error(err)
+			log.Error("Database error", "error", err)
 			return
 		}
 
 		for _, id := range channelIDs {
 			if _, err := s.db.Exec("DELETE FROM channels WHERE id = ?;", id); err != nil {
-				glog.Error(err)
+				log.Error("Database error", "error", err)
 				continue
 			}
 
 			s.hub.Unsubscribe(id)
 		}
-	}()
+	})
+
+	return nil
+}
+
+func (s *Server) callbackScheduleHandler(ctx context.Context, update tgbot.Update) error {
+	// Basic callback info
+	chatID := update.CallbackQuery.Message.Chat.ID
+	msgID := update.CallbackQuery.Message.MessageID
+
+	// Decode callback data
+	var data struct {
+		Range     ScheduleRange `json:"r"`
+		ChannelID string        `json:"cid"`
+		Page      int           `json:"page"`
+	}
+
+	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
+
+	lc := s.chatLocale(ctx, chatID)
+
+	text, markup, err := s.newSchedule(chatID, data.ChannelID, data.Range, data.Page, lc)
+	if err != nil {
+		return err
+	}
+
+	if markup == nil {
+		markup = &tgbot.InlineKeyboardMarkup{InlineKeyboard: [][]tgbot.InlineKeyboardButton{{}}}
+	}
+
+	cfg := tgbot.NewEditMessageTextAndMarkup(chatID, msgID, text, *markup)
+	cfg.DisableWebPagePreview = true
+	s.tgSend(ctx, cfg)
+
+	return nil
+}
+
+func (s *Server) callbackSubscribeHandler(ctx context.Context, update tgbot.Update) error {
+	// Basic callback info
+	chatID := update.CallbackQuery.Message.Chat.ID
+	msgID := update.CallbackQuery.Message.MessageID
+
+	// Decode callback data
+	var data struct {
+		ChannelID string `json:"cid"`
+	}
+
+	json.Unmarshal([]byte(update.CallbackQuery.Data), &data)
+
+	lc := s.chatLocale(ctx, chatID)
+	text := s.subscribeChannel(ctx, chatID, data.ChannelID, lc)
+
+	// Replace search results with subscription result.
+	cfg := tgbot.NewEditMessageText(chatID, msgID, text)
+	cfg.DisableWebPagePreview = true
+	s.tgSend(ctx, cfg)
 
 	return nil
 }
@@ -1,43 +1,44 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"runtime/debug"
-	"sort"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/filter"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/hub"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/i18n"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/tgbot"
 	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
-	"github.com/golang/glog"
 )
 
-func (s *Server) tgSend(c tgbot.Chattable) (tgbot.Message, error) {
+// tgSend sends c, and logs failure with the logger of ctx.
+func (s *Server) tgSend(ctx context.Context, c tgbot.Chattable) (tgbot.Message, error) {
 	msg, err := s.tg.Send(c)
 	if err != nil {
+		log := logging.FromContext(ctx).With("error", err, "config", fmt.Sprintf("%+v", c))
+
 		switch err.(type) {
 		case tgbot.Error:
-			switch cfg := c.(type) {
-			case tgbot.EditMessageTextConfig, tgbot.EditMessageReplyMarkupConfig:
+			switch c.(type) {
+			case tgbot.EditMessageTextConfig, tgbot.EditMessageCaptionConfig, tgbot.EditMessageReplyMarkupConfig:
 				const notModified = "message is not modified"
 
 				if !strings.Contains(err.Error(), notModified) {
-					glog.Error(err)
-					fmt.Printf("%+v\n", cfg)
-					debug.PrintStack()
+					log.Error("Telegram rejected edit")
 				}
 			default:
-				glog.Error(err)
-				fmt.Printf("%+v\n", cfg)
-				debug.PrintStack()
+				log.Error("Telegram rejected request")
 			}
 		default:
-			glog.Warning(err)
+			log.Warning("Telegram request failed")
 		}
 	}
 
@@ -45,76 +46,58 @@ func (s *Server) tgSend(c tgbot.Chattable) (tgbot.Message, error) {
 }
 
 // chAddHandler handles channel subscribe request.
-func (s *Server) chAddHandler(update tgbot.Update) {
+func (s *Server) chAddHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	chatID := update.Message.Chat.ID
 	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
 
 	// Command with out parameters.
 	if len(elements) == 1 {
 		msgConfig := tgbot.NewMessage(
 			chatID,
-			"Please use "+tgbot.InlineCode("/add <channel url> ...")+" to subscribe\\.",
+			fmt.Sprintf(lc.text("subscribe.usage"), tgbot.InlineCode(tgbot.EscapeText("/add <channel url|search terms> ..."))),
 		)
 
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
+		return
+	}
+
+	// Search channels if none of parameters is a channel reference.
+	var search bool = func() bool {
+		for _, e := range elements[1:] {
+			if ytapi.IsChannelReference(e) {
+				return false
+			}
+		}
+		return true
+	}()
+
+	if search {
+		s.chSearchHandler(ctx, chatID, strings.Join(elements[1:], " "), lc)
 		return
 	}
 
 	// Loop over every parameters.
 	for _, e := range elements[1:] {
-		var title string = e
-		var msgTemplate string
 		var msgConfig tgbot.MessageConfig
 
 		// Validation url parameter.
-		if b, err := isValidYtChannel(e); err == nil && b {
+		if channelID, b, err := s.resolveYtChannel(e); err == nil && b {
 			// If e is a valid yt channel...
-			_, url, _ := followRedirectURL(e)
-			channelID := strings.Split(url.Path, "/")[2]
-
-			// Get channel snippet from YouTube.
-			c, err := s.yt.GetChannel(channelID, []string{"snippet"})
-			if err != nil {
-				switch err.(type) {
-				case ytapi.InvalidChannelIDError:
-					msgTemplate = "%s %s failed.\n" + fmt.Sprintf("Invalid channel ID: %s.", channelID)
-				default:
-					glog.Warning(err)
-					msgTemplate = "%s %s failed.\nInternal server error."
-				}
-			} else {
-				title = c.Snippet.Title
-				// Insert into database.
-				if err := s.db.subscribe(chatID, Channel{id: c.Id, title: c.Snippet.Title}); err != nil {
-					glog.Warning(err)
-					msgTemplate = "%s %s failed.\nInternal server error."
-				}
-			}
-
-			// Run subscription
-			if msgTemplate == "" {
-				s.hub.Subscribe(c.Id)
-				msgTemplate = "%s %s successful."
-			}
-
-			title = tgbot.EscapeText(title)
-			msgTemplate = tgbot.EscapeText(msgTemplate)
-			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-				msgTemplate,
-				tgbot.ItalicText(tgbot.BordText("Subscribe")),
-				tgbot.InlineLink(title, e),
-			))
+			msgConfig = tgbot.NewMessage(chatID, s.subscribeChannel(ctx, chatID, channelID, lc))
 		} else if err != nil {
 			// If valid check failed...
-			glog.Warning(err)
+			log.Warning("YouTube API error", "error", err)
 			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-				"Subscribe %s failed, internal server error",
+				lc.text("subscribe.internal_error"),
 				tgbot.EscapeText(e),
 			))
 		} else if !b {
 			// If e isn't a valid yt channel...
 			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-				"%s is not a valid YouTube channel",
+				lc.text("channel.invalid"),
 				tgbot.EscapeText(e),
 			))
 		}
@@ -122,171 +105,719 @@ func (s *Server) chAddHandler(update tgbot.Update) {
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
 
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
+	}
+}
+
+// subscribeChannel subscribes channel for chat, returns the result message text.
+func (s *Server) subscribeChannel(ctx context.Context, chatID int64, channelID string, lc locale) string {
+	log := logging.FromContext(ctx)
+
+	var title string = channelID
+	var msgTemplate string
+
+	// Get channel snippet from YouTube.
+	c, err := s.yt.GetChannel(channelID, []string{"snippet"})
+	if err != nil {
+		switch err.(type) {
+		case ytapi.InvalidChannelIDError:
+			// Keep the first two verbs for action & link.
+			msgTemplate = fmt.Sprintf(lc.text("subscribe.failed.invalid"), "%s", "%s", tgbot.EscapeText(channelID))
+		default:
+			log.Warning("YouTube API error", "error", err)
+			msgTemplate = lc.text("subscribe.failed.internal")
+		}
+	} else {
+		title = c.Snippet.Title
+		// Insert into database.
+		if err := s.db.subscribe(chatID, Channel{id: c.Id, title: c.Snippet.Title}); err != nil {
+			log.Error("Database error", "error", err)
+			msgTemplate = lc.text("subscribe.failed.internal")
+		} else if err := s.db.setChannelHandle(c.Id, c.Snippet.CustomUrl); err != nil {
+			log.Error("Database error", "error", err)
+		}
+	}
+
+	// Run subscription
+	if msgTemplate == "" {
+		s.hub.Subscribe(c.Id)
+		msgTemplate = lc.text("subscribe.success")
 	}
+
+	title = tgbot.EscapeText(title)
+	return fmt.Sprintf(
+		msgTemplate,
+		tgbot.ItalicText(tgbot.BordText(lc.text("action.subscribe"))),
+		tgbot.InlineLink(title, ytChannelURLPrefix+channelID),
+	)
 }
 
 // chListHandler handles list subscribed channels request.
-func (s *Server) chListHandler(update tgbot.Update) {
+func (s *Server) chListHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	chatID := update.Message.Chat.ID
+	lc := s.chatLocale(ctx, chatID)
 
 	var msgConfig tgbot.MessageConfig
 	defer func() {
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 	}()
 
-	markup, err := s.newChannelListMarkUp(chatID, 0)
+	markup, err := s.newChannelListMarkUp(chatID, 0, lc)
 	if err != nil {
-		glog.Error(err)
-		msgConfig = tgbot.NewMessage(chatID, "Can not list subscribed channels.\nInternal server error.")
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("list.failed"))
 	} else {
-		msgConfig = tgbot.NewMessage(chatID, "You already subscribed following channels:")
+		msgConfig = tgbot.NewMessage(chatID, lc.text("list.header"))
 		msgConfig.ReplyMarkup = markup
 	}
 }
 
-func (s *Server) remindHandler(update tgbot.Update) {
+func (s *Server) remindHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, update.Message.Chat.ID)
 
 	if len(elements) == 1 {
 		msgConfig := tgbot.NewMessage(
 			update.Message.Chat.ID,
-			"Please use "+tgbot.InlineCode("/remind <video url> ...")+" to set video reminder\\.",
+			fmt.Sprintf(lc.text("remind.usage"), tgbot.InlineCode(tgbot.EscapeText("/remind <video url> ..."))),
 		)
 
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 		return
 	}
 
 	for _, e := range elements[1:] {
 		chatID := update.Message.Chat.ID
 
-		if b, err := s.isValidYtVideo(e); err == nil && b {
-			_, url, _ := followRedirectURL(e)
-			videoID := url.Query()["v"][0]
+		if videoID, b, err := s.resolveYtVideo(e); err == nil && b {
 
 			if _, err := s.db.Exec(
 				"INSERT IGNORE INTO notices (videoID, chatID, messageID) VALUES (?, ?, ?);",
 				videoID, chatID, -1,
 			); err != nil {
-				glog.Error(err)
-
-				msgTemplate := "%s %s failed.\nInternal server error."
-				msgTemplate = tgbot.EscapeText(msgTemplate)
+				log.Error("Database error", "error", err)
 
 				msgConfig := tgbot.NewMessage(chatID, fmt.Sprintf(
-					msgTemplate,
-					tgbot.ItalicText(tgbot.BordText("Remind")),
+					lc.text("subscribe.failed.internal"),
+					tgbot.ItalicText(tgbot.BordText(lc.text("action.remind"))),
 					tgbot.InlineLink(videoID, e),
 				))
 
 				msgConfig.DisableNotification = true
 				msgConfig.DisableWebPagePreview = true
 
-				s.tgSend(msgConfig)
+				s.tgSend(ctx, msgConfig)
 				continue
 			}
 
-			entry := hub.Entry{VideoID: videoID}
+			feed := hub.Feed{Entry: &hub.Entry{VideoID: videoID}}
 
-			go s.noticeHandler(hub.Feed{Entry: &entry})
+			s.clock.Go(func() { s.noticeHandler(ctx, feed) })
 		}
 	}
 }
 
-func (s *Server) scheduleHandler(update tgbot.Update) {
+func (s *Server) scheduleHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
 
 	var msgConfig tgbot.MessageConfig
 	defer func() {
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 	}()
 
-	var results []struct {
-		vID, vTitle string
-		chTitle     string
-		vStartTime  int64
-	}
-
-	err := s.db.queryResults(
-		&results,
-		func(rows *sql.Rows, dest interface{}) error {
-			res := dest.(*struct {
-				vID, vTitle string
-				chTitle     string
-				vStartTime  int64
-			})
-			return rows.Scan(&res.vID, &res.vTitle, &res.chTitle, &res.vStartTime)
-		},
-		"SELECT videos.id, videos.title, videos.channelTitle, videos.startTime "+
-			"FROM notices INNER JOIN videos ON notices.videoID = videos.id "+
-			"WHERE notices.chatID = ?;",
-		chatID,
-	)
+	params := elements[1:]
+
+	r := AllRange
+	if len(params) != 0 {
+		if v, ok := scheduleRanges[strings.ToLower(params[0])]; ok {
+			r = v
+			params = params[1:]
+		}
+	}
+
+	var channelID string
+	if len(params) != 0 {
+		channels, err := s.db.getChannelsByChatID(chatID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("schedule.failed"))
+			return
+		}
 
+		query := strings.Join(params, " ")
+		ch, ok := matchSubscribedChannel(channels, query)
+		if !ok {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+				lc.text("channel.not_subscribed"),
+				tgbot.InlineCode(tgbot.EscapeText(query)),
+			)+"\n"+fmt.Sprintf(
+				lc.text("schedule.usage"),
+				tgbot.InlineCode(tgbot.EscapeText("/schedule [today|tomorrow|week] [channel]")),
+			))
+			return
+		}
+
+		channelID = ch.id
+	}
+
+	text, markup, err := s.newSchedule(chatID, channelID, r, 0, lc)
 	if err != nil {
-		glog.Error(err)
-		msgConfig = tgbot.NewMessage(chatID, "Can not list live schedule.\nInternal server error.")
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("schedule.failed"))
 		return
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].vStartTime < results[j].vStartTime
-	})
+	msgConfig = tgbot.NewMessage(chatID, text)
+	if markup != nil {
+		msgConfig.ReplyMarkup = markup
+	}
+}
+
+// timezoneHandler handles chat timezone setting request.
+func (s *Server) timezoneHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	// Show current timezone.
+	if len(elements) == 1 {
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("timezone.current"),
+			tgbot.InlineCode(tgbot.EscapeText(lc.loc.String())),
+			tgbot.InlineCode(tgbot.EscapeText("/timezone <IANA name|reset>")),
+			tgbot.InlineCode(tgbot.EscapeText("/timezone Asia/Tokyo")),
+		))
+		return
+	}
 
-	var list []string
+	name := elements[1]
 
-	for _, r := range results {
-		if r.vStartTime < time.Now().Unix() {
-			continue
+	if name == "reset" {
+		if _, err := s.db.Exec("DELETE FROM timezones WHERE chatID = ?;", chatID); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("timezone.reset_fail"))
+			return
 		}
 
-		text := fmt.Sprintf(
-			"%s %s\n%s",
-			time.Unix(r.vStartTime, 0).Local().Format("01/02 15:04"),
-			tgbot.ItalicText(tgbot.EscapeText(r.chTitle)),
-			tgbot.InlineLink(
-				tgbot.BordText(tgbot.EscapeText(r.vTitle)),
-				ytVideoURLPrefix+r.vID,
-			),
-		)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("timezone.reset"),
+			tgbot.InlineCode(tgbot.EscapeText(time.Local.String())),
+		))
+		return
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("timezone.invalid"),
+			tgbot.InlineCode(tgbot.EscapeText(name)),
+		))
+		return
+	}
+
+	if _, err := s.db.Exec(
+		"INSERT INTO timezones (chatID, name) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE name = VALUES(name);",
+		chatID, loc.String(),
+	); err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("timezone.set_fail"))
+		return
+	}
+
+	msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+		lc.text("timezone.set"),
+		tgbot.InlineCode(tgbot.EscapeText(loc.String())),
+		tgbot.EscapeText(formatTime(s.clock.Now(), loc)),
+	))
+}
+
+// languageHandler handles chat language setting request.
+func (s *Server) languageHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	// Show current language.
+	if len(elements) == 1 {
+		var available []string
+		for _, lang := range i18n.Languages() {
+			available = append(available, fmt.Sprintf(
+				"%s %s",
+				tgbot.InlineCode(tgbot.EscapeText(lang)),
+				tgbot.EscapeText(i18n.Name(lang)),
+			))
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("language.current"),
+			tgbot.EscapeText(i18n.Name(lc.lang)),
+			tgbot.InlineCode(tgbot.EscapeText("/language <code>")),
+			strings.Join(available, ", "),
+		))
+		return
+	}
+
+	lang := i18n.Match(elements[1])
+	if lang == "" {
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("language.invalid"),
+			tgbot.InlineCode(tgbot.EscapeText(elements[1])),
+		))
+		return
+	}
+
+	if _, err := s.db.Exec(
+		"INSERT INTO languages (chatID, lang) VALUES (?, ?) "+
+			"ON DUPLICATE KEY UPDATE lang = VALUES(lang);",
+		chatID, lang,
+	); err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("language.set_fail"))
+		return
+	}
+
+	// Reply in the new language.
+	lc.lang = lang
+	msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+		lc.text("language.set"),
+		tgbot.EscapeText(i18n.Name(lang)),
+	))
+}
+
+// photoHandler handles thumbnail photo notice setting request.
+func (s *Server) photoHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	usage := tgbot.InlineCode(tgbot.EscapeText("/photo <on|off>"))
+
+	// Show current setting.
+	if len(elements) == 1 {
+		photo, err := s.db.isPhotoChat(chatID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+			return
+		}
+
+		state := lc.text("photo.off")
+		if photo {
+			state = lc.text("photo.on")
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("photo.current"), state, usage))
+		return
+	}
+
+	var err error
+	switch strings.ToLower(elements[1]) {
+	case "on":
+		_, err = s.db.Exec("INSERT IGNORE INTO photoChats (chatID) VALUES (?);", chatID)
+	case "off":
+		_, err = s.db.Exec("DELETE FROM photoChats WHERE chatID = ?;", chatID)
+	default:
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("photo.usage"), usage))
+		return
+	}
+
+	if err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("photo.set_fail"))
+		return
+	}
+
+	if strings.ToLower(elements[1]) == "on" {
+		msgConfig = tgbot.NewMessage(chatID, lc.text("photo.enabled"))
+	} else {
+		msgConfig = tgbot.NewMessage(chatID, lc.text("photo.disabled"))
+	}
+}
+
+// optOutHandler handles restricted videos opt-out request.
+func (s *Server) optOutHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	usage := tgbot.InlineCode(tgbot.EscapeText("/optout [members|age|region on|off] <channel url|*>"))
+
+	if len(elements) != 2 && len(elements) != 4 {
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("optout.usage"), usage))
+		return
+	}
+
+	var kind string
+	var on bool
+	if len(elements) == 4 {
+		for _, k := range optOutKinds {
+			if strings.ToLower(elements[1]) == k {
+				kind = k
+			}
+		}
+
+		switch strings.ToLower(elements[2]) {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		default:
+			kind = ""
+		}
+
+		if kind == "" {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("optout.usage"), usage))
+			return
+		}
+	}
+
+	channel := elements[len(elements)-1]
+
+	var channelID, heading string
+
+	if channel == globalFilterChannel {
+		channelID = globalFilterChannel
+		heading = tgbot.BordText(lc.text("filter.global"))
+	} else if id, b, err := s.resolveYtChannel(channel); err == nil && b {
+		channelID = id
+
+		chTitle, err := s.db.getChannelTitle(channelID)
+		if err != nil {
+			channel = tgbot.EscapeText(channel)
+			if err == sql.ErrNoRows {
+				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("channel.not_subscribed"), channel))
+			} else {
+				log.Error("Database error", "error", err)
+				msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+			}
+			return
+		}
+
+		heading = tgbot.InlineLink(tgbot.EscapeText(chTitle), ytChannelURLPrefix+channelID)
+	} else if err != nil {
+		log.Warning("YouTube API error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+		return
+	} else {
+		channel = tgbot.EscapeText(channel)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("channel.invalid"), channel))
+		return
+	}
+
+	// Set opt-out of kind, only the row of given channel is touched.
+	if kind != "" {
+		var err error
+		if on {
+			_, err = s.db.Exec(
+				"INSERT IGNORE INTO optOuts (chatID, channelID, kind) VALUES (?, ?, ?);",
+				chatID, channelID, kind,
+			)
+		} else {
+			_, err = s.db.Exec(
+				"DELETE FROM optOuts WHERE chatID = ? AND channelID = ? AND kind = ?;",
+				chatID, channelID, kind,
+			)
+		}
+
+		if err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("optout.set_fail"))
+			return
+		}
+	}
+
+	// Show effective opt-out states of channel, chat-wide opt-outs apply to every channel.
+	kinds, err := s.db.getOptOutKinds(chatID, channelID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+		msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+		return
+	}
+
+	var globalKinds []string
+	if channelID != globalFilterChannel {
+		if globalKinds, err = s.db.getOptOutKinds(chatID, globalFilterChannel); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("common.internal"))
+			return
+		}
+	}
+
+	lines := []string{heading}
+	for _, k := range optOutKinds {
+		state := lc.text("optout.notify")
+		if containsString(kinds, k) {
+			state = lc.text("optout.skip")
+		} else if containsString(globalKinds, k) {
+			state = lc.text("optout.skip_all")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", lc.text("optout."+k), state))
+	}
+	lines = append(lines, fmt.Sprintf(lc.text("optout.usage"), usage))
+
+	msgConfig = tgbot.NewMessage(chatID, strings.Join(lines, "\n"))
+}
+
+// templateHandler handles chat notification message template request.
+func (s *Server) templateHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
+	chatID := update.Message.Chat.ID
+	lc := s.chatLocale(ctx, chatID)
+
+	var msgConfig tgbot.MessageConfig
+	defer func() {
+		msgConfig.DisableNotification = true
+		msgConfig.DisableWebPagePreview = true
+		s.tgSend(ctx, msgConfig)
+	}()
+
+	// Split sub command & template content, content keeps its line breaks.
+	text := update.Message.Text
+	rest := strings.TrimSpace(text[len(strings.Fields(text)[0]):])
+	var sub, content string
+	if fields := strings.Fields(rest); len(fields) != 0 {
+		sub = fields[0]
+		content = strings.TrimSpace(rest[len(sub):])
+	}
+
+	switch sub {
+	case "set":
+		fields := newNoticeFields(sampleNoticeVideo(lc.now), lc)
+		preview, err := renderNoticeTemplate(content, fields)
+		if err != nil {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+				lc.text("template.invalid"),
+				tgbot.EscapeText(err.Error()),
+			))
+			return
+		}
+
+		if _, err := s.db.Exec(
+			"INSERT INTO templates (chatID, content) VALUES (?, ?) "+
+				"ON DUPLICATE KEY UPDATE content = VALUES(content);",
+			chatID, content,
+		); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("template.set_fail"))
+			return
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("%s\n\n%s", lc.text("template.set"), preview))
+	case "preview":
+		// Preview given content, or current template.
+		if content == "" {
+			var err error
+			if content, err = s.db.getChatTemplate(chatID); err != nil {
+				log.Error("Database error", "error", err)
+			}
+		}
+
+		video := sampleNoticeVideo(lc.now)
+
+		if content == "" {
+			msgConfig = tgbot.NewMessage(chatID, newNotifyMessageText(video, lc))
+			return
+		}
+
+		preview, err := renderNoticeTemplate(content, newNoticeFields(video, lc))
+		if err != nil {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+				lc.text("template.invalid"),
+				tgbot.EscapeText(err.Error()),
+			))
+			return
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, preview)
+	case "reset":
+		if _, err := s.db.Exec("DELETE FROM templates WHERE chatID = ?;", chatID); err != nil {
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("template.reset_fail"))
+			return
+		}
+
+		msgConfig = tgbot.NewMessage(chatID, lc.text("template.reset"))
+	default:
+		content, err := s.db.getChatTemplate(chatID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+		}
+
+		current := lc.text("template.default")
+		if content != "" {
+			current = tgbot.CodeBlock(tgbot.EscapeText(content))
+		}
+
+		var funcs []string
+		for _, name := range []string{"bold", "italic", "code", "link"} {
+			funcs = append(funcs, tgbot.InlineCode(name))
+		}
+
+		var fields []string
+		for _, name := range noticeFieldNames {
+			fields = append(fields, tgbot.InlineCode("."+name))
+		}
 
-		list = append(list, text)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			lc.text("template.current"),
+			current,
+			tgbot.InlineCode(tgbot.EscapeText("/template set <template>")),
+			tgbot.InlineCode(tgbot.EscapeText("/template preview [template]")),
+			tgbot.InlineCode(tgbot.EscapeText("/template reset")),
+			strings.Join(fields, ", "),
+			strings.Join(funcs, ", "),
+		))
+	}
+}
+
+// filterFlags are flags of /filter, which end the expression of -expr.
+var filterFlags = []string{"-show", "-test", "-blacklist", "-whitelist", "-expr"}
+
+// extractFilterExpr cuts the expression following -expr out of command text.
+// The expression ends at the next flag outside quotes, or before the last
+// field, which is the channel. It returns the remaining fields, and nil
+// expression if there's no -expr.
+func extractFilterExpr(text string) ([]string, *string) {
+	type field struct {
+		s          string
+		start, end int
+	}
+
+	var fields []field
+	for i := 0; i < len(text); {
+		for i < len(text) && unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+
+		start := i
+		for i < len(text) && !unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+
+		if i > start {
+			fields = append(fields, field{text[start:i], start, i})
+		}
 	}
 
-	if len(list) == 0 {
-		list = append(list, "No upcoming live streams\\.")
+	var elements []string
+	for _, f := range fields {
+		elements = append(elements, f.s)
 	}
 
-	msgConfig = tgbot.NewMessage(chatID, strings.Join(list, "\n"))
+	// The command itself & the channel can't be -expr.
+	k := 1
+	for k < len(fields)-1 && fields[k].s != "-expr" {
+		k++
+	}
+	if k >= len(fields)-1 {
+		return elements, nil
+	}
+
+	end := k + 1
+	for quoted := false; end < len(fields)-1; end++ {
+		if !quoted && containsString(filterFlags, fields[end].s) {
+			break
+		} else if strings.Count(fields[end].s, `"`)%2 == 1 {
+			quoted = !quoted
+		}
+	}
+
+	var content string
+	if end > k+1 {
+		content = text[fields[k+1].start:fields[end-1].end]
+	}
+
+	return append(elements[:k:k], elements[end:]...), &content
 }
 
-func (s *Server) filterHandler(update tgbot.Update) {
+func (s *Server) filterHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	chatID := update.Message.Chat.ID
 	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
 
 	var msgConfig tgbot.MessageConfig
 	defer func() {
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 	}()
 
 	if len(elements) == 1 {
 		msgConfig = tgbot.NewMessage(
 			chatID,
 			fmt.Sprintf(
-				"Please use `%s` to set filter\\.",
-				tgbot.EscapeText("/filter [-show] [-blacklist <word> ...] [-whitelist <word> ...] <channel url>"),
+				lc.text("filter.usage"),
+				tgbot.InlineCode(tgbot.EscapeText("/filter [-show|-test] [-blacklist <word> ...] [-whitelist <word> ...] [-expr <expression>] <channel url|*>")),
 			),
 		)
 		return
 	}
 
+	// Filter expression may contain spaces & quotes, extract it from raw text.
+	elements, exprText := extractFilterExpr(update.Message.Text)
+
+	if exprText != nil && *exprText != "--" {
+		if _, err := filter.Parse(*exprText); err != nil {
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+				lc.text("filter.expr_invalid"),
+				tgbot.InlineCode(tgbot.EscapeText(err.Error())),
+			))
+			return
+		}
+	}
+
 	var show bool = func() bool {
 		for i, e := range elements {
 			if e == "-show" {
@@ -297,6 +828,16 @@ func (s *Server) filterHandler(update tgbot.Update) {
 		return false
 	}()
 
+	var test bool = func() bool {
+		for i, e := range elements {
+			if e == "-test" {
+				elements = append(elements[:i], elements[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}()
+
 	var channel string
 	var blacklist, whitelist []string
 	var container *[]string
@@ -322,130 +863,190 @@ func (s *Server) filterHandler(update tgbot.Update) {
 		*container = append(*container, elements[len(elements)-1])
 	}
 
-	if b, err := isValidYtChannel(channel); err == nil && b {
+	var channelID, heading string
+
+	if channel == globalFilterChannel {
+		// Chat-wide filters.
+		channelID = globalFilterChannel
+		heading = tgbot.BordText(lc.text("filter.global"))
+	} else if id, b, err := s.resolveYtChannel(channel); err == nil && b {
 		// If channel is a valid yt channel...
-		_, url, _ := followRedirectURL(channel)
-		channelID := strings.Split(url.Path, "/")[2]
+		channelID = id
 
 		var chTitle string
 		err := s.db.QueryRow("SELECT title FROM channels WHERE id = ?;", channelID).Scan(&chTitle)
 		if err != nil {
 			channel = tgbot.EscapeText(channel)
 			if err == sql.ErrNoRows {
-				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("You have not subscribed to %s", channel))
+				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("channel.not_subscribed"), channel))
 			} else {
-				glog.Error(err)
-				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("Filter setup on %s failed, internal server error", channel))
+				log.Error("Database error", "error", err)
+				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.setup_fail"), channel))
 			}
 			return
-		} else {
-			// If Show only
-			if show {
-				type rowFilter struct {
-					block   bool
-					content string
-				}
-
-				var err error
-				var filters []rowFilter
+		}
 
-				err = s.db.queryResults(
-					&filters,
-					func(rows *sql.Rows, dest interface{}) error {
-						filter := dest.(*rowFilter)
-						return rows.Scan(&filter.block, &filter.content)
-					},
-					"SELECT block, content FROM filters WHERE chatID = ? AND channelID = ?;",
-					chatID, channelID,
-				)
+		heading = tgbot.InlineLink(tgbot.EscapeText(chTitle), ytChannelURLPrefix+channelID)
+	} else if err != nil {
+		// If valid check failed...
+		log.Warning("YouTube API error", "error", err)
+		channel := tgbot.EscapeText(channel)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.setup_fail"), channel))
+		return
+	} else {
+		// If channel isn't a valid yt channel...
+		channel := tgbot.EscapeText(channel)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("channel.invalid"), channel))
+		return
+	}
 
-				if err != nil {
-					glog.Error(err)
-					channel := tgbot.EscapeText(channel)
-					msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("Filter show on %s failed, internal server error", channel))
-					return
-				}
+	// If Test only
+	if test {
+		text, err := s.newFilterTestText(ctx, chatID, channelID, lc)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			channel := tgbot.EscapeText(channel)
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.show_fail"), channel))
+			return
+		}
 
-				var black, white string
-				for _, f := range filters {
-					if f.block {
-						black = f.content
-					} else {
-						white = f.content
-					}
-				}
+		msgConfig = tgbot.NewMessage(chatID, text)
+		return
+	}
 
-				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-					"%s\n\n_blacklist:_\n%s\n\n_whitelist:_\n%s",
-					tgbot.InlineLink(
-						tgbot.EscapeText(chTitle),
-						tgbot.EscapeText(channel),
-					),
-					tgbot.EscapeText(black),
-					tgbot.EscapeText(white),
-				))
+	// If Show only
+	if show {
+		black, white, err := s.db.getFilterLists(chatID, channelID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			channel := tgbot.EscapeText(channel)
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.show_fail"), channel))
+			return
+		}
 
-				return
-			}
+		expr, err := s.db.getFilterExpr(chatID, channelID)
+		if err != nil {
+			log.Error("Database error", "error", err)
+			channel := tgbot.EscapeText(channel)
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.show_fail"), channel))
+			return
+		}
 
-			// Regular add filter
-			_, err := s.db.Exec(
-				"INSERT INTO filters (chatID, channelID, block, content) VALUES(?, ?, ?, ?) "+
-					"ON DUPLICATE KEY UPDATE content = VALUES(content);",
-				chatID, channelID, true, strings.Join(blacklist, ","),
-			)
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+			"%s\n\n%s",
+			heading,
+			newFilterListsText(lc, black, white, expr),
+		))
 
-			if err != nil {
-				glog.Error(err)
-				channel := tgbot.EscapeText(channel)
-				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("Filter setup on %s failed, internal server error", channel))
-				return
-			}
+		return
+	}
 
+	// Set filter expression
+	if exprText != nil {
+		var err error
+		if *exprText == "--" {
 			_, err = s.db.Exec(
-				"INSERT INTO filters (chatID, channelID, block, content) VALUES(?, ?, ?, ?) "+
-					"ON DUPLICATE KEY UPDATE content = VALUES(content);",
-				chatID, channelID, false, strings.Join(whitelist, ","),
+				"DELETE FROM filterExprs WHERE chatID = ? AND channelID = ?;",
+				chatID, channelID,
 			)
+		} else {
+			_, err = s.db.Exec(
+				"INSERT INTO filterExprs (chatID, channelID, expr) VALUES (?, ?, ?) "+
+					"ON DUPLICATE KEY UPDATE expr = VALUES(expr);",
+				chatID, channelID, *exprText,
+			)
+		}
 
-			if err != nil {
-				glog.Error(err)
-				channel := tgbot.EscapeText(channel)
-				msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("Filter setup on %s failed, internal server error", channel))
-				return
-			}
+		if err != nil {
+			log.Error("Database error", "error", err)
+			channel := tgbot.EscapeText(channel)
+			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.setup_fail"), channel))
+			return
+		}
 
+		// Keep word lists if only expression is given.
+		if len(blacklist) == 0 && len(whitelist) == 0 {
 			msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-				"%s\n\n_blacklist:_\n%s\n\n_whitelist:_\n%s",
-				tgbot.InlineLink(
-					tgbot.EscapeText(chTitle),
-					tgbot.EscapeText(channel),
-				),
-				tgbot.EscapeText(strings.Join(blacklist, ",")),
-				tgbot.EscapeText(strings.Join(whitelist, ",")),
+				"%s\n\n%s",
+				heading,
+				lc.text("filter.updated"),
 			))
+			return
 		}
-	} else if err != nil {
-		// If valid check failed...
-		glog.Warning(err)
+	}
+
+	// Regular add filter
+	_, err := s.db.Exec(
+		"INSERT INTO filters (chatID, channelID, block, content) VALUES(?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE content = VALUES(content);",
+		chatID, channelID, true, strings.Join(blacklist, ","),
+	)
+
+	if err != nil {
+		log.Error("Database error", "error", err)
 		channel := tgbot.EscapeText(channel)
-		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("Filter setup on %s failed, internal server error", channel))
-	} else if !b {
-		// If channel isn't a valid yt channel...
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.setup_fail"), channel))
+		return
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO filters (chatID, channelID, block, content) VALUES(?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE content = VALUES(content);",
+		chatID, channelID, false, strings.Join(whitelist, ","),
+	)
+
+	if err != nil {
+		log.Error("Database error", "error", err)
 		channel := tgbot.EscapeText(channel)
-		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf("%s is not a valid YouTube channel", channel))
+		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(lc.text("filter.setup_fail"), channel))
+		return
 	}
+
+	expr, err := s.db.getFilterExpr(chatID, channelID)
+	if err != nil {
+		log.Error("Database error", "error", err)
+	}
+
+	msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
+		"%s\n\n%s",
+		heading,
+		newFilterListsText(lc, strings.Join(blacklist, ","), strings.Join(whitelist, ","), expr),
+	))
 }
 
-func (s *Server) autoRecordHandler(update tgbot.Update) {
+// newFilterListsText renders blacklist & whitelist contents.
+func newFilterListsText(lc locale, black, white, expr string) string {
+	text := fmt.Sprintf(
+		"%s\n%s\n\n%s\n%s",
+		tgbot.ItalicText(lc.text("filter.blacklist")),
+		tgbot.EscapeText(black),
+		tgbot.ItalicText(lc.text("filter.whitelist")),
+		tgbot.EscapeText(white),
+	)
+
+	if expr != "" {
+		text += fmt.Sprintf(
+			"\n\n%s\n%s",
+			tgbot.ItalicText(lc.text("filter.expression")),
+			tgbot.InlineCode(tgbot.EscapeText(expr)),
+		)
+	}
+
+	return text
+}
+
+func (s *Server) autoRecordHandler(ctx context.Context, update tgbot.Update) {
+	log := logging.FromContext(ctx)
+
 	chatID := update.Message.Chat.ID
 	elements := strings.Fields(update.Message.Text)
+	lc := s.chatLocale(ctx, chatID)
 
 	var msgConfig tgbot.MessageConfig
 	defer func() {
 		msgConfig.DisableNotification = true
 		msgConfig.DisableWebPagePreview = true
-		s.tgSend(msgConfig)
+		s.tgSend(ctx, msgConfig)
 	}()
 
 	// Help.
@@ -453,8 +1054,8 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 		msgConfig = tgbot.NewMessage(
 			chatID,
 			fmt.Sprintf(
-				"Please use `%s` to set autorecorder\\.",
-				tgbot.EscapeText("~autorecord [-show] [-remove] <channel url> ..."),
+				lc.text("autorecord.usage"),
+				tgbot.InlineCode(tgbot.EscapeText("~autorc [-show] [-remove] <channel url> ...")),
 			),
 		)
 		return
@@ -490,8 +1091,8 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 		)
 
 		if err != nil {
-			glog.Error(err)
-			msgConfig = tgbot.NewMessage(chatID, "Failed to show autorecords, internal server error")
+			log.Error("Database error", "error", err)
+			msgConfig = tgbot.NewMessage(chatID, lc.text("autorecord.show_fail"))
 			return
 		}
 
@@ -502,13 +1103,13 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 				channelText,
 				tgbot.InlineLink(
 					tgbot.EscapeText(ch.title),
-					"https://www.youtube.com/channel/"+ch.id,
+					ytChannelURLPrefix+ch.id,
 				),
 			)
 		}
 
 		msgConfig = tgbot.NewMessage(chatID, fmt.Sprintf(
-			"You already set autorecorder on these channels:\n%s",
+			lc.text("autorecord.list"),
 			strings.Join(channelText, "\n"),
 		))
 		return
@@ -518,11 +1119,8 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 	var msgText []string
 
 	for _, channel := range elements[1:] {
-		var channelID string
-		if b, err := isValidYtChannel(channel); err == nil && b {
+		if channelID, b, err := s.resolveYtChannel(channel); err == nil && b {
 			// If e is a valid yt channel...
-			_, url, _ := followRedirectURL(channel)
-			channelID = strings.Split(url.Path, "/")[2]
 
 			// Check whether user subscribed this channel
 			var exists bool
@@ -535,15 +1133,16 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 			).Scan(&exists, &chTitle)
 
 			chTitle = tgbot.EscapeText(chTitle)
+			link := ytChannelURLPrefix + channelID
 
 			if err != nil {
 				channel = tgbot.EscapeText(channel)
 
 				if err == sql.ErrNoRows {
-					msgText = append(msgText, fmt.Sprintf("You have not subscribed to %s", channel))
+					msgText = append(msgText, fmt.Sprintf(lc.text("channel.not_subscribed"), channel))
 				} else {
-					glog.Error(err)
-					msgText = append(msgText, fmt.Sprintf("Failed to modify autorecorder on %s, internal server error", channel))
+					log.Error("Database error", "error", err)
+					msgText = append(msgText, fmt.Sprintf(lc.text("autorecord.modify_fail"), channel))
 				}
 
 				continue
@@ -551,8 +1150,8 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 				msgText = append(
 					msgText,
 					fmt.Sprintf(
-						"You have not subscribed to %s",
-						tgbot.InlineLink(chTitle, channel),
+						lc.text("channel.not_subscribed"),
+						tgbot.InlineLink(chTitle, link),
 					),
 				)
 
@@ -565,64 +1164,65 @@ func (s *Server) autoRecordHandler(update tgbot.Update) {
 					"INSERT IGNORE INTO autorecords (chatID, channelID) VALUES (?, ?);",
 					chatID, channelID,
 				); err != nil {
-					glog.Error(err)
-					channel = tgbot.EscapeText(channel)
+					log.Error("Database error", "error", err)
 					msgText = append(msgText, fmt.Sprintf(
-						"Failed to modify autorecorder on %s, internal server error",
-						tgbot.InlineLink(chTitle, channel),
+						lc.text("autorecord.modify_fail"),
+						tgbot.InlineLink(chTitle, link),
 					))
 					continue
 				}
 
-				msgText = append(msgText, fmt.Sprintf("Add autorecorder on %s", tgbot.InlineLink(chTitle, channel)))
+				msgText = append(msgText, fmt.Sprintf(lc.text("autorecord.added"), tgbot.InlineLink(chTitle, link)))
 			} else {
 				// Remove channel from autorecorder table
 				if _, err = s.db.Exec(
 					"DELETE FROM autorecords WHERE chatID = ? AND channelID = ?;",
 					chatID, channelID,
 				); err != nil {
-					glog.Error(err)
+					log.Error("Database error", "error", err)
 					msgText = append(msgText, fmt.Sprintf(
-						"Failed to modify autorecorder on %s, internal server error",
-						tgbot.InlineLink(chTitle, channel),
+						lc.text("autorecord.modify_fail"),
+						tgbot.InlineLink(chTitle, link),
 					))
 					continue
 				}
 
-				msgText = append(msgText, fmt.Sprintf("Remove autorecorder on %s", tgbot.InlineLink(chTitle, channel)))
+				msgText = append(msgText, fmt.Sprintf(lc.text("autorecord.removed"), tgbot.InlineLink(chTitle, link)))
 			}
 		} else if err != nil {
 			// If valid check failed...
-			glog.Warning(err)
+			log.Warning("YouTube API error", "error", err)
 			channel = tgbot.EscapeText(channel)
-			msgText = append(msgText, fmt.Sprintf("Failed to add autorecorder to %s, internal server error", channel))
+			msgText = append(msgText, fmt.Sprintf(lc.text("autorecord.add_fail"), channel))
 		} else if !b {
 			// If e isn't a valid yt channel...
 			channel = tgbot.EscapeText(channel)
-			msgText = append(msgText, fmt.Sprintf("%s is not a valid YouTube channel", channel))
+			msgText = append(msgText, fmt.Sprintf(lc.text("channel.invalid"), channel))
 		}
 	}
 
 	// Emtpy message
 	if len(msgText) == 0 {
-		msgText = append(msgText, "Empty parameters\\.")
+		msgText = append(msgText, lc.text("common.empty_params"))
 	}
 
 	msgConfig = tgbot.NewMessage(chatID, strings.Join(msgText, "\n"))
 }
 
-func (s *Server) downloadHandler(update tgbot.Update) {
+func (s *Server) downloadHandler(ctx context.Context, update tgbot.Update) {
 	chatID := update.Message.Chat.ID
 	elements := strings.Fields(update.Message.Text)
 
+	lc := s.chatLocale(ctx, chatID)
+
 	var msgConfig tgbot.MessageConfig
-	var internalServerError tgbot.MessageConfig = tgbot.NewMessage(chatID, "Download request failed, internal server error")
+	var internalServerError tgbot.MessageConfig = tgbot.NewMessage(chatID, lc.text("download.internal"))
 
 	defer func() {
 		if msgConfig != (tgbot.MessageConfig{}) {
 			msgConfig.DisableNotification = true
 			msgConfig.DisableWebPagePreview = true
-			s.tgSend(msgConfig)
+			s.tgSend(ctx, msgConfig)
 		}
 	}()
 
@@ -631,35 +1231,34 @@ func (s *Server) downloadHandler(update tgbot.Update) {
 
 		data["url"] = elements[1:]
 
-		resp, err := r.Download(s.CallbackUrl(), data)
+		resp, err := r.Download(ctx, s.CallbackUrl(), data)
 
 		if err != nil {
 			if err.(*url.Error).Timeout() {
-				msgConfig = tgbot.NewMessage(chatID, "Download request failed, connection timeout")
+				msgConfig = tgbot.NewMessage(chatID, lc.text("download.timeout"))
 			} else {
-				glog.Error(err)
 				msgConfig = internalServerError
 			}
 		} else if resp.StatusCode != http.StatusOK {
 			respBody, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
-				glog.Error(err)
+				logging.FromContext(ctx).Error("Failed to read recorder response", "error", err)
 				msgConfig = internalServerError
 				return
 			}
 
-			fmt.Println(string(respBody))
+			logging.FromContext(ctx).Warning("Recorder rejected download", "status", resp.StatusCode, "body", string(respBody))
 			msgConfig = tgbot.NewMessage(
 				chatID,
-				fmt.Sprintf("Download request failed with status code %d, please check your recorder", resp.StatusCode),
+				fmt.Sprintf(lc.text("download.status"), resp.StatusCode),
 			)
 		} else {
-			msgConfig = tgbot.NewMessage(chatID, "Download request has been accepted")
+			msgConfig = tgbot.NewMessage(chatID, lc.text("download.accepted"))
 		}
 	}
 }
 
-func (s *Server) internalServerErrorCallback(callbackID string) {
-	cfg := tgbot.NewCallback(callbackID, "Internal server error")
+func (s *Server) internalServerErrorCallback(ctx context.Context, callbackID string, lc locale) {
+	cfg := tgbot.NewCallback(callbackID, lc.raw("common.internal"))
 	s.tg.AnswerCallbackQuery(cfg)
 }
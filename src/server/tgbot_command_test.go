@@ -0,0 +1,38 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractFilterExpr(t *testing.T) {
+	const url = "https://www.youtube.com/channel/UC38IQsAvIsxxjztdMZQtwHA"
+
+	tests := []struct {
+		text     string
+		elements []string
+		expr     string
+		ok       bool
+	}{
+		{"/filter -show " + url, []string{"/filter", "-show", url}, "", false},
+		{"/filter -expr minecraft " + url, []string{"/filter", url}, "minecraft", true},
+		{`/filter -expr "a  castle" -members ` + url, []string{"/filter", url}, `"a  castle" -members`, true},
+		{"/filter -expr foo -blacklist a " + url, []string{"/filter", "-blacklist", "a", url}, "foo", true},
+		{"/filter -expr foo -test " + url, []string{"/filter", "-test", url}, "foo", true},
+		{"/filter -test -expr foo " + url, []string{"/filter", "-test", url}, "foo", true},
+		{`/filter -expr "foo -test" ` + url, []string{"/filter", url}, `"foo -test"`, true},
+		{"/filter -expr -show " + url, []string{"/filter", "-show", url}, "", true},
+		{"/filter -expr " + url, []string{"/filter", url}, "", true},
+	}
+
+	for _, tt := range tests {
+		elements, expr := extractFilterExpr(tt.text)
+
+		if !reflect.DeepEqual(elements, tt.elements) {
+			t.Errorf("extractFilterExpr(%q) elements = %q, want %q", tt.text, elements, tt.elements)
+		}
+		if (expr != nil) != tt.ok || expr != nil && *expr != tt.expr {
+			t.Errorf("extractFilterExpr(%q) expr = %v, want %q, %v", tt.text, expr, tt.expr, tt.ok)
+		}
+	}
+}
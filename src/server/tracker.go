@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
+)
+
+const (
+	minTrackInterval = 30 * time.Second
+	maxTrackInterval = 5 * time.Minute
+
+	// Viewers change ratio which is regarded as unstable.
+	unstableViewersRatio = 0.1
+
+	// Transient YouTube API errors are retried with doubling delay.
+	trackRetries    = 3
+	trackRetryDelay = 10 * time.Second
+)
+
+// tryLiveTracker starts a live tracker if the video is live and not tracked yet,
+// which inherits correlation ID of ctx.
+func (s *Server) tryLiveTracker(ctx context.Context, video *ytapi.Video) {
+	if !ytapi.IsLiveLiveBroadcast(video) {
+		return
+	}
+
+	s.trackerMutex.Lock()
+	defer s.trackerMutex.Unlock()
+
+	if _, ok := s.trackerTable[video.Id]; ok {
+		return
+	}
+
+	s.trackerTable[video.Id] = true
+
+	videoID := video.Id
+
+	s.clock.Go(func() {
+		s.liveTracker(ctx, videoID)
+
+		s.trackerMutex.Lock()
+		delete(s.trackerTable, videoID)
+		s.trackerMutex.Unlock()
+	})
+}
+
+// liveTracker samples concurrent viewers & updates notices until the live ends.
+func (s *Server) liveTracker(ctx context.Context, videoID string) {
+	ctx = logging.NewContext(ctx, "tracker", "live")
+	log := logging.FromContext(ctx)
+
+	log.Info("Running live tracker", "url", ytVideoURLPrefix+videoID)
+
+	var last uint64
+	interval := minTrackInterval
+	first := true
+
+	for {
+		v, err := s.getTrackedVideo(ctx, videoID)
+		if err != nil {
+			log.Warning("YouTube API error", "error", err)
+			return
+		} else if !ytapi.IsLiveLiveBroadcast(v) {
+			// Live is over, send final notices & stop tracking.
+			log.Info("Live ended")
+			s.sendNotices(ctx, v)
+			return
+		}
+
+		count := v.LiveStreamingDetails.ConcurrentViewers
+		if count != 0 {
+			if _, err := s.db.Exec(
+				"INSERT IGNORE INTO viewers (videoID, time, count) VALUES (?, ?, ?);",
+				v.Id, s.clock.Now().Unix(), count,
+			); err != nil {
+				log.Error("Database error", "error", err)
+			}
+		}
+
+		// Notices only show viewers, there's nothing to edit if unchanged.
+		if first || count != last {
+			s.sendNotices(ctx, v)
+		}
+
+		interval = nextTrackInterval(interval, last, count)
+		last = count
+		first = false
+
+		s.clock.Sleep(interval)
+	}
+}
+
+// getTrackedVideo requests video, retries transient errors with backoff.
+func (s *Server) getTrackedVideo(ctx context.Context, videoID string) (*ytapi.Video, error) {
+	delay := trackRetryDelay
+
+	for i := 0; ; i++ {
+		v, err := s.yt.GetVideo(videoID, noticeVideoParts)
+		if err == nil || i == trackRetries {
+			return v, err
+		}
+
+		logging.FromContext(ctx).Warning("YouTube API error, retrying", "error", err, "delay", delay.String())
+
+		s.clock.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// nextTrackInterval samples more frequently while viewers are changing rapidly,
+// and backs off while viewers are stable.
+func nextTrackInterval(interval time.Duration, last, current uint64) time.Duration {
+	var ratio float64 = 1
+
+	if last != 0 {
+		ratio = (float64(current) - float64(last)) / float64(last)
+		if ratio < 0 {
+			ratio = -ratio
+		}
+	}
+
+	if ratio > unstableViewersRatio {
+		interval /= 2
+	} else {
+		interval *= 2
+	}
+
+	if interval < minTrackInterval {
+		interval = minTrackInterval
+	} else if interval > maxTrackInterval {
+		interval = maxTrackInterval
+	}
+
+	return interval
+}
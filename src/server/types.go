@@ -11,6 +11,7 @@ type Notice struct {
 	videoID   string
 	chatID    int64
 	messageID int
+	photo     bool
 }
 
 type Chat struct {
@@ -18,3 +19,16 @@ type Chat struct {
 	recorder sql.NullString
 	token    sql.NullString
 }
+
+type Summary struct {
+	videoID   string
+	chatID    int64
+	messageID int
+	endTime   int64
+	title     string
+}
+
+type ViewerSample struct {
+	Time  int64
+	Count int64
+}
@@ -1,69 +1,104 @@
 package server
 
 import (
-	"net/http"
-	"net/url"
+	"fmt"
 	"strings"
-)
+	"time"
 
-const (
-	ytHost = "www.youtube.com"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/ytapi"
 )
 
-func followRedirectURL(rawurl string) (bool, *url.URL, error) {
-	url, err := url.Parse(rawurl)
-	if err != nil {
-		return false, nil, nil
+// resolveYtChannel resolves channel ID from any form of YouTube channel URL.
+// It reports false without error if rawurl isn't a valid channel.
+func (s *Server) resolveYtChannel(rawurl string) (string, bool, error) {
+	channelID, err := s.yt.ResolveChannelID(rawurl)
+	if _, ok := err.(ytapi.InvalidChannelIDError); ok {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
 	}
 
-	if url.Scheme == "" {
-		url.Scheme = "https"
-	}
+	return channelID, true, nil
+}
 
-	resp, err := http.Get(url.String())
-	if err != nil {
-		return false, nil, err
-	} else if resp.StatusCode != http.StatusOK {
-		return false, nil, nil
-	} else {
-		return true, resp.Request.URL, nil
+// resolveYtVideo returns video ID of YouTube video URL.
+// It reports false without error if rawurl isn't a valid video.
+func (s *Server) resolveYtVideo(rawurl string) (string, bool, error) {
+	videoID, ok := ytapi.ParseVideoID(rawurl)
+	if !ok {
+		return "", false, nil
 	}
-}
 
-func isValidYtChannel(rawurl string) (bool, error) {
-	ok, url, err := followRedirectURL(rawurl)
+	videos, err := s.yt.GetVideos([]string{videoID}, []string{"snippet"})
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 
-	return ok && url.Host == ytHost && strings.HasPrefix(url.Path, "/channel"), nil
+	return videoID, len(videos) != 0, nil
 }
 
-func (s *Server) isValidYtVideo(rawurl string) (bool, error) {
-	ok, url, err := followRedirectURL(rawurl)
-	if err != nil {
-		return false, err
-	}
-
-	if ok && url.Host == ytHost && strings.HasPrefix(url.Path, "/watch") {
-		videoID := url.Query()["v"][0]
-		videos, err := s.yt.GetVideos([]string{videoID}, []string{"snippet"})
-		if err != nil {
-			return false, err
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
 		}
-
-		return len(videos) != 0, nil
 	}
 
-	return false, nil
+	return false
 }
 
-func containsAny(s string, substrs []string) bool {
-	for _, sub := range substrs {
-		if strings.Contains(s, sub) {
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
 			return true
 		}
 	}
 
 	return false
 }
+
+// formatDuration formats duration as hh:mm:ss.
+func formatDuration(dur time.Duration) string {
+	dur = dur.Round(time.Second)
+
+	return fmt.Sprintf(
+		"%02d:%02d:%02d",
+		int(dur.Hours()),
+		int(dur.Minutes())%60,
+		int(dur.Seconds())%60,
+	)
+}
+
+const timeLayout = "2006/01/02 15:04:05"
+
+// formatTime formats t in the given location.
+func formatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(timeLayout)
+}
+
+// formatDurationShort formats duration as short form rounded to minute, e.g. "2h 15m" or "3d 4h".
+// Returns empty string if it's shorter than a minute.
+func formatDurationShort(dur time.Duration) string {
+	if dur < 0 {
+		dur = -dur
+	}
+
+	dur = dur.Round(time.Minute)
+
+	days := int(dur.Hours()) / 24
+	hours := int(dur.Hours()) % 24
+	minutes := int(dur.Minutes()) % 60
+
+	var parts []string
+	if days != 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours != 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes != 0 && days == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
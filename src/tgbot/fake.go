@@ -0,0 +1,266 @@
+package tgbot
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	api "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// RecordKind is the kind of operation recorded by Fake.
+type RecordKind int
+
+const (
+	// Sent is a new message, includes photos & documents.
+	Sent RecordKind = iota
+	// Edited is an edit of text, caption or reply markup.
+	Edited
+	// Deleted is a deleted message.
+	Deleted
+	// Answered is an answered callback or inline query.
+	Answered
+)
+
+func (k RecordKind) String() string {
+	return [...]string{"sent", "edited", "deleted", "answered"}[k]
+}
+
+// Record is an operation recorded by Fake.
+type Record struct {
+	Kind      RecordKind
+	ChatID    int64
+	MessageID int
+	// Text is the text or caption of message.
+	Text string
+	// Markup is the reply markup of message, nil if none.
+	Markup interface{}
+	// Config is the original config sent.
+	Config interface{}
+}
+
+// Fake is an in-memory Sender for offline testing, which records every
+// message sent, edited & deleted.
+type Fake struct {
+	// Fail returns error for config which should fail, nil means accept all.
+	Fail func(config interface{}) error
+
+	mutex    sync.Mutex
+	nextID   int
+	records  []Record
+	messages map[int64]map[int]Record
+}
+
+var _ Sender = (*Fake)(nil)
+
+// NewFake returns a pointer to a new `Fake`.
+func NewFake() *Fake {
+	return &Fake{
+		nextID:   1,
+		messages: make(map[int64]map[int]Record),
+	}
+}
+
+// Records returns all recorded operations in order.
+func (f *Fake) Records() []Record {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]Record(nil), f.records...)
+}
+
+// Reset clears recorded operations, but keeps existing messages.
+func (f *Fake) Reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.records = nil
+}
+
+// Message returns the current state of message, reports false if it doesn't exist.
+func (f *Fake) Message(chatID int64, messageID int) (Record, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	r, ok := f.messages[chatID][messageID]
+	return r, ok
+}
+
+// Messages returns the current state of every message in chat, ordered by message ID.
+func (f *Fake) Messages(chatID int64) []Record {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var results []Record
+	for id := 1; id < f.nextID; id++ {
+		if r, ok := f.messages[chatID][id]; ok {
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+// Send records c as sent or edited message.
+func (f *Fake) Send(c Chattable) (Message, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.Fail != nil {
+		if err := f.Fail(c); err != nil {
+			return Message{}, err
+		}
+	}
+
+	switch cfg := c.(type) {
+	case MessageConfig:
+		return f.send(cfg.ChatID, cfg.Text, cfg.ReplyMarkup, cfg), nil
+	case PhotoConfig:
+		return f.send(cfg.ChatID, cfg.Caption, cfg.ReplyMarkup, cfg), nil
+	case api.DocumentConfig:
+		return f.send(cfg.ChatID, cfg.Caption, cfg.ReplyMarkup, cfg), nil
+	case EditMessageTextConfig:
+		return f.edit(cfg.BaseEdit, &cfg.Text, cfg)
+	case EditMessageCaptionConfig:
+		return f.edit(cfg.BaseEdit, &cfg.Caption, cfg)
+	case EditMessageReplyMarkupConfig:
+		return f.edit(cfg.BaseEdit, nil, cfg)
+	case DeleteMessageConfig:
+		_, err := f.delete(cfg)
+		return Message{}, err
+	}
+
+	return Message{}, fmt.Errorf("fake: unsupported config %T", c)
+}
+
+func (f *Fake) send(chatID int64, text string, markup interface{}, config interface{}) Message {
+	r := Record{
+		Kind:      Sent,
+		ChatID:    chatID,
+		MessageID: f.nextID,
+		Text:      text,
+		Markup:    markup,
+		Config:    config,
+	}
+	f.nextID++
+
+	if f.messages[chatID] == nil {
+		f.messages[chatID] = make(map[int]Record)
+	}
+
+	f.messages[chatID][r.MessageID] = r
+	f.records = append(f.records, r)
+
+	return Message{MessageID: r.MessageID, Chat: &api.Chat{ID: chatID}, Text: text}
+}
+
+func (f *Fake) edit(edit api.BaseEdit, text *string, config interface{}) (Message, error) {
+	r, ok := f.messages[edit.ChatID][edit.MessageID]
+	if !ok {
+		return Message{}, Error{Code: 400, Message: "Bad Request: message to edit not found"}
+	}
+
+	// Editing without reply markup removes it, same as Telegram.
+	var markup interface{}
+	if edit.ReplyMarkup != nil {
+		markup = *edit.ReplyMarkup
+	}
+
+	newText := r.Text
+	if text != nil {
+		newText = *text
+	}
+
+	if newText == r.Text && reflect.DeepEqual(markup, normalizeMarkup(r.Markup)) {
+		return Message{}, Error{
+			Code:    400,
+			Message: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same",
+		}
+	}
+
+	r.Kind = Edited
+	r.Text = newText
+	r.Markup = markup
+	r.Config = config
+
+	f.messages[edit.ChatID][edit.MessageID] = r
+	f.records = append(f.records, r)
+
+	return Message{MessageID: r.MessageID, Chat: &api.Chat{ID: edit.ChatID}, Text: r.Text}, nil
+}
+
+// normalizeMarkup dereferences inline keyboard markup, so it's comparable with edits.
+func normalizeMarkup(markup interface{}) interface{} {
+	if m, ok := markup.(*InlineKeyboardMarkup); ok && m != nil {
+		return *m
+	}
+
+	return markup
+}
+
+func (f *Fake) delete(config DeleteMessageConfig) (APIResponse, error) {
+	r, ok := f.messages[config.ChatID][config.MessageID]
+	if !ok {
+		return APIResponse{}, Error{Code: 400, Message: "Bad Request: message to delete not found"}
+	}
+
+	delete(f.messages[config.ChatID], config.MessageID)
+
+	r.Kind = Deleted
+	r.Config = config
+	f.records = append(f.records, r)
+
+	return APIResponse{Ok: true}, nil
+}
+
+// DeleteMessage records message as deleted.
+func (f *Fake) DeleteMessage(config DeleteMessageConfig) (APIResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.Fail != nil {
+		if err := f.Fail(config); err != nil {
+			return APIResponse{}, err
+		}
+	}
+
+	return f.delete(config)
+}
+
+// GetMe returns the fake bot user.
+func (f *Fake) GetMe() (User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.Fail != nil {
+		if err := f.Fail(User{}); err != nil {
+			return User{}, err
+		}
+	}
+
+	return User{ID: 1, IsBot: true, FirstName: "Fake", UserName: "fake_bot"}, nil
+}
+
+// AnswerCallbackQuery records callback query as answered.
+func (f *Fake) AnswerCallbackQuery(config CallbackConfig) (APIResponse, error) {
+	return f.answer(config)
+}
+
+// AnswerInlineQuery records inline query as answered.
+func (f *Fake) AnswerInlineQuery(config InlineConfig) (APIResponse, error) {
+	return f.answer(config)
+}
+
+func (f *Fake) answer(config interface{}) (APIResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.Fail != nil {
+		if err := f.Fail(config); err != nil {
+			return APIResponse{}, err
+		}
+	}
+
+	f.records = append(f.records, Record{Kind: Answered, Config: config})
+	return APIResponse{Ok: true}, nil
+}
@@ -0,0 +1,163 @@
+package tgbot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testChatID = -1001
+
+func newTestMarkup() InlineKeyboardMarkup {
+	return NewInlineKeyboardMarkup(NewInlineKeyboardRow(NewInlineKeyboardButtonData("Record", "data")))
+}
+
+func TestFakeSendAndEdit(t *testing.T) {
+	f := NewFake()
+
+	msgConfig := NewMessage(testChatID, "upcoming")
+	msgConfig.ReplyMarkup = newTestMarkup()
+
+	msg, err := f.Send(msgConfig)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// Edit text with the same markup.
+	edit := NewEditMessageTextAndMarkup(testChatID, msg.MessageID, "live", newTestMarkup())
+	if _, err := f.Send(edit); err != nil {
+		t.Fatalf("Send(edit) error = %v", err)
+	}
+
+	r, ok := f.Message(testChatID, msg.MessageID)
+	if !ok || r.Kind != Edited || r.Text != "live" || r.Markup == nil {
+		t.Errorf("Message() = %+v, %v, want edited text with markup", r, ok)
+	}
+
+	// Editing without markup removes it.
+	if _, err := f.Send(NewEditMessageText(testChatID, msg.MessageID, "live")); err != nil {
+		t.Fatalf("Send(edit) error = %v", err)
+	}
+
+	if r, _ := f.Message(testChatID, msg.MessageID); r.Markup != nil {
+		t.Errorf("markup after edit without markup = %+v, want nil", r.Markup)
+	}
+
+	// Caption edits are recorded as text.
+	if _, err := f.Send(NewEditMessageCaption(testChatID, msg.MessageID, "ended")); err != nil {
+		t.Fatalf("Send(edit caption) error = %v", err)
+	}
+
+	if r, _ := f.Message(testChatID, msg.MessageID); r.Text != "ended" {
+		t.Errorf("text after caption edit = %q, want %q", r.Text, "ended")
+	}
+
+	kinds := []RecordKind{Sent, Edited, Edited, Edited}
+	records := f.Records()
+	if len(records) != len(kinds) {
+		t.Fatalf("Records() = %d records, want %d", len(records), len(kinds))
+	}
+	for i, k := range kinds {
+		if records[i].Kind != k || records[i].MessageID != msg.MessageID {
+			t.Errorf("Records()[%d] = %v of %d, want %v of %d", i, records[i].Kind, records[i].MessageID, k, msg.MessageID)
+		}
+	}
+}
+
+func TestFakeEditNotModified(t *testing.T) {
+	f := NewFake()
+
+	msgConfig := NewMessage(testChatID, "upcoming")
+	msgConfig.ReplyMarkup = newTestMarkup()
+	msg, _ := f.Send(msgConfig)
+
+	tests := []struct {
+		name   string
+		config Chattable
+	}{
+		{"same text & markup", NewEditMessageTextAndMarkup(testChatID, msg.MessageID, "upcoming", newTestMarkup())},
+		{"same markup", NewEditMessageReplyMarkup(testChatID, msg.MessageID, newTestMarkup())},
+	}
+
+	for _, tt := range tests {
+		_, err := f.Send(tt.config)
+
+		var e Error
+		if !errors.As(err, &e) || !strings.Contains(e.Message, "message is not modified") {
+			t.Errorf("%s: Send() error = %v, want not modified", tt.name, err)
+		}
+	}
+
+	if n := len(f.Records()); n != 1 {
+		t.Errorf("Records() = %d records, want only the sent one", n)
+	}
+
+	// Removing markup is a modification.
+	if _, err := f.Send(NewEditMessageText(testChatID, msg.MessageID, "upcoming")); err != nil {
+		t.Errorf("Send(edit removing markup) error = %v", err)
+	}
+}
+
+func TestFakeDelete(t *testing.T) {
+	f := NewFake()
+
+	first, _ := f.Send(NewMessage(testChatID, "first"))
+	second, _ := f.Send(NewMessage(testChatID, "second"))
+	f.Send(NewMessage(testChatID+1, "other chat"))
+
+	if _, err := f.DeleteMessage(NewDeleteMessage(testChatID, first.MessageID)); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+
+	if _, ok := f.Message(testChatID, first.MessageID); ok {
+		t.Error("Message() of deleted message exists")
+	}
+
+	messages := f.Messages(testChatID)
+	if len(messages) != 1 || messages[0].MessageID != second.MessageID {
+		t.Errorf("Messages() = %+v, want only the second message", messages)
+	}
+
+	// Deleted messages can't be deleted or edited again.
+	if _, err := f.DeleteMessage(NewDeleteMessage(testChatID, first.MessageID)); err == nil {
+		t.Error("DeleteMessage() of deleted message error = nil")
+	}
+	if _, err := f.Send(NewEditMessageText(testChatID, first.MessageID, "edited")); err == nil {
+		t.Error("Send(edit) of deleted message error = nil")
+	}
+
+	records := f.Records()
+	if last := records[len(records)-1]; last.Kind != Deleted || last.MessageID != first.MessageID {
+		t.Errorf("last record = %v of %d, want deleted of %d", last.Kind, last.MessageID, first.MessageID)
+	}
+}
+
+func TestFakeFail(t *testing.T) {
+	f := NewFake()
+	f.Fail = func(config interface{}) error {
+		if _, ok := config.(PhotoConfig); ok {
+			return Error{Code: 400, Message: "Bad Request: wrong file identifier"}
+		}
+		return nil
+	}
+
+	if _, err := f.Send(NewPhotoShare(testChatID, "file", "caption")); err == nil {
+		t.Error("Send(photo) error = nil, want failure")
+	}
+	if _, err := f.Send(NewMessage(testChatID, "text")); err != nil {
+		t.Errorf("Send(message) error = %v", err)
+	}
+
+	records := f.Records()
+	if len(records) != 1 || records[0].Text != "text" {
+		t.Errorf("Records() = %+v, want only the message", records)
+	}
+
+	f.Reset()
+	if n := len(f.Records()); n != 0 {
+		t.Errorf("Records() after Reset() = %d records, want 0", n)
+	}
+	if n := len(f.Messages(testChatID)); n != 1 {
+		t.Errorf("Messages() after Reset() = %d messages, want 1", n)
+	}
+}
@@ -26,6 +26,11 @@ func InlineCode(text string) string {
 	return fmt.Sprintf("`%s`", text)
 }
 
+// CodeBlock transforms text into markdown pre-formatted code block.
+func CodeBlock(text string) string {
+	return fmt.Sprintf("```\n%s\n```", text)
+}
+
 // EscapeText takes an input text and escape Telegram markup symbols.
 // In this way we can send a text without being afraid of having to escape the characters manually.
 // Note that you don't have to include the formatting style in the input text, or it will be escaped too.
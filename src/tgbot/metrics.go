@@ -0,0 +1,78 @@
+package tgbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
+)
+
+var sends = metrics.NewCounter(
+	"notifier_telegram_sends_total",
+	"Telegram requests by config type & outcome.",
+	"config", "outcome",
+)
+
+// meteredSender is a Sender which records metrics of every request.
+type meteredSender struct {
+	Sender
+}
+
+// Metered returns a Sender which records metrics of requests to s.
+func Metered(s Sender) Sender {
+	return meteredSender{s}
+}
+
+// configName returns the type name of config, e.g. "MessageConfig".
+func configName(config interface{}) string {
+	name := fmt.Sprintf("%T", config)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// observe records a request with its error.
+func observe(name string, err error) {
+	outcome := "ok"
+	if err != nil {
+		if e, ok := err.(Error); !ok {
+			outcome = "network"
+		} else if strings.Contains(e.Message, "message is not modified") {
+			outcome = "not_modified"
+		} else if e.Code == 429 {
+			outcome = "rate_limited"
+		} else {
+			outcome = "rejected"
+		}
+	}
+
+	sends.Inc(name, outcome)
+}
+
+func (s meteredSender) Send(c Chattable) (Message, error) {
+	msg, err := s.Sender.Send(c)
+	observe(configName(c), err)
+	return msg, err
+}
+
+func (s meteredSender) GetMe() (User, error) {
+	user, err := s.Sender.GetMe()
+	observe("GetMe", err)
+	return user, err
+}
+
+func (s meteredSender) AnswerCallbackQuery(config CallbackConfig) (APIResponse, error) {
+	resp, err := s.Sender.AnswerCallbackQuery(config)
+	observe(configName(config), err)
+	return resp, err
+}
+
+func (s meteredSender) AnswerInlineQuery(config InlineConfig) (APIResponse, error) {
+	resp, err := s.Sender.AnswerInlineQuery(config)
+	observe(configName(config), err)
+	return resp, err
+}
+
+func (s meteredSender) DeleteMessage(config DeleteMessageConfig) (APIResponse, error) {
+	resp, err := s.Sender.DeleteMessage(config)
+	observe(configName(config), err)
+	return resp, err
+}
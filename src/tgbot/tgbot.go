@@ -12,6 +12,22 @@ type TgBot struct {
 	*api.BotAPI
 }
 
+// Sender is the Telegram Bot API calls used by server.
+// It's implemented by TgBot, and Fake for offline testing.
+type Sender interface {
+	Send(c Chattable) (Message, error)
+	AnswerCallbackQuery(config CallbackConfig) (APIResponse, error)
+	AnswerInlineQuery(config InlineConfig) (APIResponse, error)
+	DeleteMessage(config DeleteMessageConfig) (APIResponse, error)
+	GetMe() (User, error)
+}
+
+var _ Sender = (*TgBot)(nil)
+
+// APIResponse is a response from the Telegram API with the result
+// stored raw.
+type APIResponse = api.APIResponse
+
 // Update is an update response, from GetUpdates.
 type Update = api.Update
 
@@ -81,11 +97,64 @@ func NewEditMessageTextAndMarkup(chatID int64, messageID int, text string, reply
 	return editMsgConfig
 }
 
+// PhotoConfig contains information about a SendPhoto request.
+type PhotoConfig = api.PhotoConfig
+
+// FileBytes contains information about a set of bytes to upload
+// as a File.
+type FileBytes = api.FileBytes
+
+// NewPhotoUpload creates a new photo uploader.
+//
+// chatID is where to send it, file is a string path to the file,
+// FileReader, or FileBytes, caption is the photo caption.
+func NewPhotoUpload(chatID int64, file interface{}, caption string) PhotoConfig {
+	photoConfig := api.NewPhotoUpload(chatID, file)
+	photoConfig.Caption = caption
+	photoConfig.ParseMode = "MarkdownV2"
+	return photoConfig
+}
+
+// NewPhotoShare shares an existing photo.
+//
+// chatID is where to send it, fileID is the ID of the file already
+// uploaded or a HTTP URL of the photo, caption is the photo caption.
+func NewPhotoShare(chatID int64, fileID string, caption string) PhotoConfig {
+	photoConfig := api.NewPhotoShare(chatID, fileID)
+	photoConfig.Caption = caption
+	photoConfig.ParseMode = "MarkdownV2"
+	return photoConfig
+}
+
+// EditMessageCaptionConfig allows you to modify the caption of a message.
+type EditMessageCaptionConfig = api.EditMessageCaptionConfig
+
+// NewEditMessageCaption allows you to edit the caption of a message.
+func NewEditMessageCaption(chatID int64, messageID int, caption string) EditMessageCaptionConfig {
+	editCaptionConfig := api.NewEditMessageCaption(chatID, messageID, caption)
+	editCaptionConfig.ParseMode = "MarkdownV2"
+	return editCaptionConfig
+}
+
 // NewDeleteMessage creates a request to delete a message.
 func NewDeleteMessage(chatID int64, messageID int) DeleteMessageConfig {
 	return api.NewDeleteMessage(chatID, messageID)
 }
 
+// InlineConfig contains information on making an InlineQuery response.
+type InlineConfig = api.InlineConfig
+
+// InlineQueryResultArticle is an inline query response article.
+type InlineQueryResultArticle = api.InlineQueryResultArticle
+
+// InputTextMessageContent contains text for displaying
+// as an inline query result.
+type InputTextMessageContent = api.InputTextMessageContent
+
+// NewInlineQueryResultArticle creates a new inline query article
+// with MarkdownV2 message text.
+var NewInlineQueryResultArticle = api.NewInlineQueryResultArticleMarkdownV2
+
 // UpdatesChannel is the channel for getting updates.
 type UpdatesChannel = api.UpdatesChannel
 
@@ -138,3 +207,9 @@ type ForceReply = api.ForceReply
 // Message is returned by almost every request, and contains data about
 // almost anything.
 type Message = api.Message
+
+// Chat contains information about the place a message was sent.
+type Chat = api.Chat
+
+// User is a user on Telegram.
+type User = api.User
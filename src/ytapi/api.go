@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/golang/glog"
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/logging"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
@@ -16,12 +16,24 @@ type YtAPI struct {
 
 const ytIDNumLimit = 50
 
+// Client is the YouTube API calls used by server.
+// It's implemented by YtAPI, and Fake for offline testing.
+type Client interface {
+	GetChannel(channelID string, parts []string) (*Channel, error)
+	GetChannels(channelIDs, parts []string) ([]*Channel, error)
+	GetVideo(videoID string, parts []string) (*Video, error)
+	GetVideos(videoIDs, parts []string) ([]*Video, error)
+	ResolveChannelID(rawurl string) (string, error)
+	SearchChannels(query string, maxResults int64, parts []string) ([]*Channel, error)
+	Ping() error
+}
+
 // NewYtAPI ...
 func NewYtAPI(apiKey string) *YtAPI {
 	ctx := context.Background()
 	service, err := youtube.NewService(ctx, option.WithAPIKey(apiKey), option.WithScopes(youtube.YoutubeReadonlyScope))
 	if err != nil {
-		glog.Fatalln("Error creating YouTube client:", err)
+		logging.With("error", err).Fatal("Failed to create YouTube client")
 	}
 
 	return &YtAPI{service}
@@ -86,9 +98,48 @@ func (api *YtAPI) getChannelListResponse(channelIDs, part []string) (*youtube.Ch
 	return resp, nil
 }
 
+// SearchChannels returns channels matched query, ordered by relevance.
+func (api *YtAPI) SearchChannels(query string, maxResults int64, parts []string) ([]*Channel, error) {
+	call := api.Search.List([]string{"snippet"})
+	call = call.Type("channel").Q(query).MaxResults(maxResults)
+
+	resp, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	var channelIDs []string
+	for _, item := range resp.Items {
+		channelIDs = append(channelIDs, item.Id.ChannelId)
+	}
+
+	channels, err := api.GetChannels(channelIDs, parts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the order of search results.
+	table := make(map[string]*Channel)
+	for _, c := range channels {
+		table[c.Id] = c
+	}
+
+	var results []*Channel
+	for _, id := range channelIDs {
+		if c, ok := table[id]; ok {
+			results = append(results, c)
+		}
+	}
+
+	return results, nil
+}
+
 // Video is *video* resource represents a YouTube video.
 type Video = youtube.Video
 
+// Thumbnail is a thumbnail image of a YouTube resource.
+type Thumbnail = youtube.Thumbnail
+
 // GetVideo ...
 func (api *YtAPI) GetVideo(videoID string, parts []string) (*Video, error) {
 	videos, err := api.GetVideos([]string{videoID}, parts)
@@ -128,6 +179,12 @@ func (api *YtAPI) GetVideos(videoIDs, parts []string) ([]*youtube.Video, error)
 	return videos, nil
 }
 
+// Ping checks YouTube API is reachable by the cheapest call, which costs 1 quota unit.
+func (api *YtAPI) Ping() error {
+	_, err := api.I18nLanguages.List([]string{"id"}).Do()
+	return err
+}
+
 func (api *YtAPI) getVideoListResponse(videoIDs, part []string) (*youtube.VideoListResponse, error) {
 	call := api.Videos.List(part)
 	call = call.Id(videoIDs...)
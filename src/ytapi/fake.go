@@ -0,0 +1,345 @@
+package ytapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// VideoState is the state of a live broadcast in Fake.
+type VideoState int
+
+const (
+	// Upcoming is a scheduled live broadcast.
+	Upcoming VideoState = iota
+	// Live is a live broadcast on air.
+	Live
+	// Completed is an ended live broadcast, with its VOD processed.
+	Completed
+	// Deleted is a removed video, which is no longer returned.
+	Deleted
+)
+
+// Transition changes video to State at time At.
+type Transition struct {
+	At      time.Time
+	State   VideoState
+	Viewers uint64
+}
+
+// Fake is an in-memory Client for offline testing.
+// Videos change their state by scripted transitions as time goes by.
+type Fake struct {
+	// Now returns current time, it's time.Now if nil.
+	Now func() time.Time
+
+	mutex    sync.Mutex
+	channels map[string]*Channel
+	videos   map[string]*Video
+	deleted  map[string]bool
+	scripts  map[string][]Transition
+}
+
+var _ Client = (*Fake)(nil)
+
+// NewFake returns a pointer to a new empty `Fake`.
+func NewFake() *Fake {
+	return &Fake{
+		channels: make(map[string]*Channel),
+		videos:   make(map[string]*Video),
+		deleted:  make(map[string]bool),
+		scripts:  make(map[string][]Transition),
+	}
+}
+
+func (f *Fake) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+
+	return f.Now()
+}
+
+// AddChannel adds a channel with handle, e.g. "@name", which can be empty.
+func (f *Fake) AddChannel(channelID, title, handle string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.channels[channelID] = &Channel{
+		Id: channelID,
+		Snippet: &youtube.ChannelSnippet{
+			Title:     title,
+			CustomUrl: handle,
+		},
+		Statistics: &youtube.ChannelStatistics{},
+	}
+}
+
+// AddVideo adds an upcoming live broadcast of channel scheduled at start.
+func (f *Fake) AddVideo(channelID, videoID, title string, start time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var channelTitle string
+	if c, ok := f.channels[channelID]; ok {
+		channelTitle = c.Snippet.Title
+	}
+
+	f.videos[videoID] = &Video{
+		Id: videoID,
+		Snippet: &youtube.VideoSnippet{
+			ChannelId:            channelID,
+			ChannelTitle:         channelTitle,
+			Title:                title,
+			LiveBroadcastContent: "upcoming",
+			PublishedAt:          f.now().UTC().Format(time.RFC3339),
+		},
+		LiveStreamingDetails: &youtube.VideoLiveStreamingDetails{
+			ScheduledStartTime: start.UTC().Format(time.RFC3339),
+		},
+		ContentDetails: &youtube.VideoContentDetails{Duration: "P0D"},
+		Status:         &youtube.VideoStatus{UploadStatus: "uploaded", PrivacyStatus: "public"},
+	}
+	delete(f.deleted, videoID)
+}
+
+// PutVideo adds or replaces video as is, e.g. a normal upload.
+func (f *Fake) PutVideo(v *Video) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.videos[v.Id] = v
+	delete(f.deleted, v.Id)
+}
+
+// SetState changes video to state immediately.
+func (f *Fake) SetState(videoID string, state VideoState, viewers uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.apply(videoID, Transition{At: f.now(), State: state, Viewers: viewers})
+}
+
+// Script appends transitions of video, which are applied once their time comes.
+func (f *Fake) Script(videoID string, transitions ...Transition) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	script := append(f.scripts[videoID], transitions...)
+	sort.SliceStable(script, func(i, j int) bool { return script[i].At.Before(script[j].At) })
+	f.scripts[videoID] = script
+}
+
+// advance applies every due transitions, must be called with mutex held.
+func (f *Fake) advance() {
+	now := f.now()
+
+	for id, script := range f.scripts {
+		for len(script) != 0 && !script[0].At.After(now) {
+			f.apply(id, script[0])
+			script = script[1:]
+		}
+
+		if len(script) == 0 {
+			delete(f.scripts, id)
+		} else {
+			f.scripts[id] = script
+		}
+	}
+}
+
+func (f *Fake) apply(videoID string, t Transition) {
+	v, ok := f.videos[videoID]
+	if !ok {
+		return
+	}
+
+	at := t.At.UTC().Format(time.RFC3339)
+	details := v.LiveStreamingDetails
+	if details == nil {
+		details = &youtube.VideoLiveStreamingDetails{}
+		v.LiveStreamingDetails = details
+	}
+
+	switch t.State {
+	case Upcoming:
+		v.Snippet.LiveBroadcastContent = "upcoming"
+		details.ActualStartTime = ""
+		details.ActualEndTime = ""
+		details.ConcurrentViewers = 0
+	case Live:
+		v.Snippet.LiveBroadcastContent = "live"
+		if details.ActualStartTime == "" {
+			details.ActualStartTime = at
+		}
+		details.ConcurrentViewers = t.Viewers
+	case Completed:
+		v.Snippet.LiveBroadcastContent = "none"
+		if details.ActualStartTime == "" {
+			details.ActualStartTime = at
+		}
+		details.ActualEndTime = at
+		details.ConcurrentViewers = 0
+
+		start, _ := time.Parse(time.RFC3339, details.ActualStartTime)
+		v.ContentDetails = &youtube.VideoContentDetails{Duration: isoDuration(t.At.Sub(start))}
+		v.Status = &youtube.VideoStatus{UploadStatus: "processed", PrivacyStatus: "public"}
+	case Deleted:
+		f.deleted[videoID] = true
+	}
+}
+
+// isoDuration formats d as ISO 8601 duration, e.g. "PT1H2M3S".
+func isoDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d <= 0 {
+		return "P0D"
+	}
+
+	return fmt.Sprintf("PT%dH%dM%dS", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// GetChannel ...
+func (f *Fake) GetChannel(channelID string, parts []string) (*Channel, error) {
+	channels, _ := f.GetChannels([]string{channelID}, parts)
+	if len(channels) == 0 {
+		return nil, InvalidChannelIDError{Id: channelID}
+	}
+
+	return channels[0], nil
+}
+
+// GetChannels ...
+func (f *Fake) GetChannels(channelIDs, parts []string) ([]*Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var channels []*Channel
+	for _, id := range channelIDs {
+		if c, ok := f.channels[id]; ok {
+			copied := *c
+			channels = append(channels, &copied)
+		}
+	}
+
+	return channels, nil
+}
+
+// GetVideo ...
+func (f *Fake) GetVideo(videoID string, parts []string) (*Video, error) {
+	videos, _ := f.GetVideos([]string{videoID}, parts)
+	if len(videos) == 0 {
+		return nil, fmt.Errorf(
+			"Invalid video ID: %s. The video may not exists, not available, or be deleted",
+			videoID,
+		)
+	}
+
+	return videos[0], nil
+}
+
+// GetVideos ...
+func (f *Fake) GetVideos(videoIDs, parts []string) ([]*Video, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.advance()
+
+	var videos []*Video
+	for _, id := range videoIDs {
+		if v, ok := f.videos[id]; ok && !f.deleted[id] {
+			videos = append(videos, copyVideo(v))
+		}
+	}
+
+	return videos, nil
+}
+
+// copyVideo copies video, so callers won't see later transitions.
+func copyVideo(v *Video) *Video {
+	copied := *v
+
+	if v.Snippet != nil {
+		snippet := *v.Snippet
+		copied.Snippet = &snippet
+	}
+	if v.LiveStreamingDetails != nil {
+		details := *v.LiveStreamingDetails
+		copied.LiveStreamingDetails = &details
+	}
+	if v.ContentDetails != nil {
+		contentDetails := *v.ContentDetails
+		copied.ContentDetails = &contentDetails
+	}
+	if v.Status != nil {
+		status := *v.Status
+		copied.Status = &status
+	}
+
+	return &copied
+}
+
+// ResolveChannelID ...
+func (f *Fake) ResolveChannelID(rawurl string) (string, error) {
+	return resolveChannelID(f, rawurl)
+}
+
+func (f *Fake) channelIDByHandle(handle, rawurl string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	handle = "@" + strings.ToLower(strings.TrimPrefix(handle, "@"))
+	for id, c := range f.channels {
+		if strings.ToLower(c.Snippet.CustomUrl) == handle {
+			return id, nil
+		}
+	}
+
+	return "", InvalidChannelIDError{Id: rawurl}
+}
+
+// channelIDByUsername treats handles as legacy usernames.
+func (f *Fake) channelIDByUsername(username, rawurl string) (string, error) {
+	return f.channelIDByHandle(username, rawurl)
+}
+
+func (f *Fake) channelIDByVideo(videoID, rawurl string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if v, ok := f.videos[videoID]; ok && !f.deleted[videoID] {
+		return v.Snippet.ChannelId, nil
+	}
+
+	return "", InvalidChannelIDError{Id: rawurl}
+}
+
+// Ping always succeeds.
+func (f *Fake) Ping() error {
+	return nil
+}
+
+// SearchChannels returns channels which titles contain query.
+func (f *Fake) SearchChannels(query string, maxResults int64, parts []string) ([]*Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var channels []*Channel
+	for _, c := range f.channels {
+		if strings.Contains(strings.ToLower(c.Snippet.Title), strings.ToLower(query)) {
+			copied := *c
+			channels = append(channels, &copied)
+		}
+	}
+
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Id < channels[j].Id })
+	if int64(len(channels)) > maxResults {
+		channels = channels[:maxResults]
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,77 @@
+package ytapi
+
+import (
+	"time"
+
+	"github.com/HTYISABUG/tgbot-youtube-notifier/src/metrics"
+)
+
+var (
+	apiCalls = metrics.NewCounter(
+		"notifier_youtube_api_calls_total",
+		"YouTube API calls by method & outcome.",
+		"method", "outcome",
+	)
+	apiLatency = metrics.NewHistogram(
+		"notifier_youtube_api_call_duration_seconds",
+		"Latency of YouTube API calls by method.",
+		nil, "method",
+	)
+)
+
+// meteredClient is a Client which records metrics of every call.
+type meteredClient struct {
+	Client
+}
+
+// Metered returns a Client which records metrics of calls to c.
+func Metered(c Client) Client {
+	return meteredClient{c}
+}
+
+// observe records a call of method started at start.
+func observe(method string, start time.Time, err error) {
+	outcome := "ok"
+	if _, ok := err.(InvalidChannelIDError); err != nil && !ok {
+		// Invalid channels are user errors, not failed calls.
+		outcome = "error"
+	}
+
+	apiCalls.Inc(method, outcome)
+	apiLatency.Since(start, method)
+}
+
+func (c meteredClient) GetChannel(channelID string, parts []string) (channel *Channel, err error) {
+	defer func(start time.Time) { observe("channels.get", start, err) }(time.Now())
+	return c.Client.GetChannel(channelID, parts)
+}
+
+func (c meteredClient) GetChannels(channelIDs, parts []string) (channels []*Channel, err error) {
+	defer func(start time.Time) { observe("channels.list", start, err) }(time.Now())
+	return c.Client.GetChannels(channelIDs, parts)
+}
+
+func (c meteredClient) GetVideo(videoID string, parts []string) (video *Video, err error) {
+	defer func(start time.Time) { observe("videos.get", start, err) }(time.Now())
+	return c.Client.GetVideo(videoID, parts)
+}
+
+func (c meteredClient) GetVideos(videoIDs, parts []string) (videos []*Video, err error) {
+	defer func(start time.Time) { observe("videos.list", start, err) }(time.Now())
+	return c.Client.GetVideos(videoIDs, parts)
+}
+
+func (c meteredClient) ResolveChannelID(rawurl string) (channelID string, err error) {
+	defer func(start time.Time) { observe("resolve", start, err) }(time.Now())
+	return c.Client.ResolveChannelID(rawurl)
+}
+
+func (c meteredClient) SearchChannels(query string, maxResults int64, parts []string) (channels []*Channel, err error) {
+	defer func(start time.Time) { observe("search.list", start, err) }(time.Now())
+	return c.Client.SearchChannels(query, maxResults, parts)
+}
+
+func (c meteredClient) Ping() (err error) {
+	defer func(start time.Time) { observe("i18nLanguages.list", start, err) }(time.Now())
+	return c.Client.Ping()
+}
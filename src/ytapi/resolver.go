@@ -0,0 +1,201 @@
+package ytapi
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	channelIDRe  = regexp.MustCompile(`^UC[A-Za-z0-9_\-]{22}$`)
+	videoIDRe    = regexp.MustCompile(`^[A-Za-z0-9_\-]{11}$`)
+	customNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+)
+
+// reservedPaths are the first path segments of YouTube pages,
+// which can't be legacy custom URLs of channels.
+var reservedPaths = map[string]bool{
+	"about": true, "account": true, "c": true, "channel": true, "embed": true,
+	"feed": true, "gaming": true, "hashtag": true, "kids": true, "live": true,
+	"logout": true, "music": true, "playlist": true, "premium": true, "redirect": true,
+	"results": true, "shorts": true, "signin": true, "t": true, "upload": true,
+	"user": true, "watch": true,
+}
+
+// forHandle is a call option filters channels by handle,
+// which is not supported by the generated client yet.
+type forHandle string
+
+func (h forHandle) Get() (string, string) { return "forHandle", string(h) }
+
+// channelLookup looks up channel ID by the keys can't be parsed from URL.
+// Every lookup returns InvalidChannelIDError of rawurl if the channel doesn't exist.
+type channelLookup interface {
+	channelIDByHandle(handle, rawurl string) (string, error)
+	channelIDByUsername(username, rawurl string) (string, error)
+	channelIDByVideo(videoID, rawurl string) (string, error)
+}
+
+// ResolveChannelID maps any form of YouTube channel to its channel ID, includes
+//
+//	UCxxxxxxxxxxxxxxxxxxxxxx
+//	@handle
+//	https://www.youtube.com/channel/UCxxxxxxxxxxxxxxxxxxxxxx
+//	https://www.youtube.com/@handle
+//	https://www.youtube.com/c/name
+//	https://www.youtube.com/user/name
+//	https://www.youtube.com/name (legacy custom URL)
+//	https://www.youtube.com/watch?v=xxxxxxxxxxx (the uploader)
+//	https://youtu.be/xxxxxxxxxxx (the uploader)
+//
+// It returns InvalidChannelIDError if rawurl isn't any of them or the channel doesn't exist.
+func (api *YtAPI) ResolveChannelID(rawurl string) (string, error) {
+	return resolveChannelID(api, rawurl)
+}
+
+func resolveChannelID(l channelLookup, rawurl string) (string, error) {
+	rawurl = strings.TrimSpace(rawurl)
+
+	if channelIDRe.MatchString(rawurl) {
+		return rawurl, nil
+	} else if strings.HasPrefix(rawurl, "@") {
+		return l.channelIDByHandle(rawurl, rawurl)
+	} else if videoID, ok := ParseVideoID(rawurl); ok {
+		return l.channelIDByVideo(videoID, rawurl)
+	}
+
+	u, segments, ok := parseYtURL(rawurl)
+	if !ok {
+		return "", InvalidChannelIDError{Id: rawurl}
+	}
+
+	switch {
+	case len(segments) > 1 && segments[0] == "channel" && channelIDRe.MatchString(segments[1]):
+		return segments[1], nil
+	case len(segments) > 1 && segments[0] == "user":
+		return l.channelIDByUsername(segments[1], rawurl)
+	case len(segments) > 1 && segments[0] == "c":
+		return channelIDByCustomName(l, segments[1], rawurl)
+	case strings.HasPrefix(segments[0], "@"):
+		return l.channelIDByHandle(segments[0], rawurl)
+	case len(segments) == 1 && u.Host == "youtube.com" && isLegacyCustomName(segments[0]):
+		// Legacy custom URL without "/c".
+		return channelIDByCustomName(l, segments[0], rawurl)
+	}
+
+	return "", InvalidChannelIDError{Id: rawurl}
+}
+
+// ParseVideoID returns video ID of a YouTube video URL, e.g.
+//
+//	https://www.youtube.com/watch?v=xxxxxxxxxxx
+//	https://www.youtube.com/live/xxxxxxxxxxx
+//	https://youtu.be/xxxxxxxxxxx
+func ParseVideoID(rawurl string) (string, bool) {
+	u, segments, ok := parseYtURL(rawurl)
+	if !ok {
+		return "", false
+	}
+
+	var videoID string
+
+	switch {
+	case u.Host == "youtu.be":
+		videoID = segments[0]
+	case segments[0] == "watch":
+		videoID = u.Query().Get("v")
+	case len(segments) > 1 && (segments[0] == "live" || segments[0] == "shorts" || segments[0] == "embed"):
+		videoID = segments[1]
+	}
+
+	return videoID, videoIDRe.MatchString(videoID)
+}
+
+// parseYtURL parses rawurl of YouTube, with host normalized & path split into segments.
+func parseYtURL(rawurl string) (*url.URL, []string, bool) {
+	rawurl = strings.TrimSpace(rawurl)
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	switch host {
+	case "youtu.be", "youtube.com", "music.youtube.com":
+		u.Host = host
+	default:
+		return nil, nil, false
+	}
+
+	return u, strings.Split(strings.Trim(u.Path, "/"), "/"), true
+}
+
+// IsChannelReference reports whether s looks like a channel ID, handle or YouTube URL
+// rather than search terms.
+func IsChannelReference(s string) bool {
+	lower := strings.ToLower(s)
+
+	return channelIDRe.MatchString(s) || strings.HasPrefix(s, "@") ||
+		strings.Contains(lower, "youtube.com") || strings.Contains(lower, "youtu.be")
+}
+
+// isLegacyCustomName reports whether the only path segment of a YouTube URL
+// can be a legacy custom name, e.g. https://www.youtube.com/name.
+func isLegacyCustomName(segment string) bool {
+	return customNameRe.MatchString(segment) && !reservedPaths[strings.ToLower(segment)]
+}
+
+// channelIDByCustomName resolves custom URL name. There's no API for it,
+// but most custom names became handles, or are the legacy usernames.
+func channelIDByCustomName(l channelLookup, name, rawurl string) (string, error) {
+	channelID, err := l.channelIDByHandle(name, rawurl)
+	if _, ok := err.(InvalidChannelIDError); ok {
+		return l.channelIDByUsername(name, rawurl)
+	}
+
+	return channelID, err
+}
+
+func (api *YtAPI) channelIDByHandle(handle, rawurl string) (string, error) {
+	handle = strings.TrimPrefix(handle, "@")
+	if handle == "" {
+		return "", InvalidChannelIDError{Id: rawurl}
+	}
+
+	resp, err := api.Channels.List([]string{"id"}).Do(forHandle("@" + handle))
+	if err != nil {
+		return "", err
+	} else if len(resp.Items) == 0 {
+		return "", InvalidChannelIDError{Id: rawurl}
+	}
+
+	return resp.Items[0].Id, nil
+}
+
+func (api *YtAPI) channelIDByUsername(username, rawurl string) (string, error) {
+	resp, err := api.Channels.List([]string{"id"}).ForUsername(username).Do()
+	if err != nil {
+		return "", err
+	} else if len(resp.Items) == 0 {
+		return "", InvalidChannelIDError{Id: rawurl}
+	}
+
+	return resp.Items[0].Id, nil
+}
+
+func (api *YtAPI) channelIDByVideo(videoID, rawurl string) (string, error) {
+	resp, err := api.getVideoListResponse([]string{videoID}, []string{"snippet"})
+	if err != nil {
+		return "", err
+	} else if len(resp.Items) == 0 {
+		return "", InvalidChannelIDError{Id: rawurl}
+	}
+
+	return resp.Items[0].Snippet.ChannelId, nil
+}
@@ -0,0 +1,101 @@
+package ytapi
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	testChannelID = "UC38IQsAvIsxxjztdMZQtwHA"
+	testVideoID   = "dQw4w9WgXcQ"
+)
+
+func TestParseVideoID(t *testing.T) {
+	tests := []struct {
+		rawurl string
+		want   string
+		ok     bool
+	}{
+		{"https://www.youtube.com/watch?v=" + testVideoID, testVideoID, true},
+		{"youtube.com/watch?v=" + testVideoID + "&t=42", testVideoID, true},
+		{"https://m.youtube.com/watch?v=" + testVideoID, testVideoID, true},
+		{"https://music.youtube.com/watch?v=" + testVideoID, testVideoID, true},
+		{"https://www.youtube.com/live/" + testVideoID, testVideoID, true},
+		{"https://www.youtube.com/shorts/" + testVideoID, testVideoID, true},
+		{"https://www.youtube.com/embed/" + testVideoID, testVideoID, true},
+		{"https://youtu.be/" + testVideoID, testVideoID, true},
+		{"youtu.be/" + testVideoID + "?si=share", testVideoID, true},
+
+		{"https://www.youtube.com/watch", "", false},
+		{"https://www.youtube.com/watch?v=short", "", false},
+		{"https://www.youtube.com/live/", "", false},
+		{"https://youtu.be/", "", false},
+		{"https://youtu.be/" + testVideoID + "x", "", false},
+		{"https://www.youtube.com/channel/" + testChannelID, "", false},
+		{"https://example.com/watch?v=" + testVideoID, "", false},
+		{testVideoID, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseVideoID(tt.rawurl)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("ParseVideoID(%q) = %q, %v, want %q, %v", tt.rawurl, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResolveChannelID(t *testing.T) {
+	f := NewFake()
+	f.AddChannel(testChannelID, "Test Channel", "@testchannel")
+	f.AddVideo(testChannelID, testVideoID, "Test Live", time.Now().Add(time.Hour))
+
+	valid := []string{
+		testChannelID,
+		"@testchannel",
+		"@TestChannel",
+		"https://www.youtube.com/channel/" + testChannelID,
+		"https://www.youtube.com/channel/" + testChannelID + "/videos",
+		"youtube.com/channel/" + testChannelID,
+		"https://www.youtube.com/@testchannel",
+		"https://www.youtube.com/@testchannel/streams",
+		"https://www.youtube.com/c/testchannel",
+		"https://www.youtube.com/user/testchannel",
+		"https://www.youtube.com/testchannel",
+		"https://www.youtube.com/watch?v=" + testVideoID,
+		"https://youtu.be/" + testVideoID,
+		"  https://www.youtube.com/@testchannel  ",
+	}
+
+	for _, rawurl := range valid {
+		got, err := f.ResolveChannelID(rawurl)
+		if err != nil || got != testChannelID {
+			t.Errorf("ResolveChannelID(%q) = %q, %v, want %q", rawurl, got, err, testChannelID)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"UCtooshort",
+		"@unknown",
+		"https://www.youtube.com/",
+		"https://www.youtube.com/feed",
+		"https://www.youtube.com/feed/subscriptions",
+		"https://www.youtube.com/results?search_query=testchannel",
+		"https://www.youtube.com/watch",
+		"https://www.youtube.com/playlist?list=PL0123456789",
+		"https://www.youtube.com/channel/not-a-channel-id",
+		"https://www.youtube.com/unknown",
+		"https://music.youtube.com/testchannel",
+		"https://youtu.be/",
+		"https://youtu.be/testchannel",
+		"https://www.youtube.com/watch?v=aaaaaaaaaaa",
+		"https://example.com/@testchannel",
+	}
+
+	for _, rawurl := range invalid {
+		got, err := f.ResolveChannelID(rawurl)
+		if _, ok := err.(InvalidChannelIDError); !ok {
+			t.Errorf("ResolveChannelID(%q) = %q, %v, want InvalidChannelIDError", rawurl, got, err)
+		}
+	}
+}
@@ -1,5 +1,13 @@
 package ytapi
 
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // IsLiveBroadcast ...
 func IsLiveBroadcast(v *Video) bool {
 	return v.LiveStreamingDetails != nil
@@ -25,3 +33,110 @@ func IsCompletedLiveBroadcast(v *Video) bool {
 	return IsLiveBroadcast(v) &&
 		v.LiveStreamingDetails.ActualEndTime != ""
 }
+
+// IsProcessedVideo reports whether the video has finished processing and
+// is publicly watchable, e.g. the VOD of a completed live.
+func IsProcessedVideo(v *Video) bool {
+	return v.Status != nil && v.ContentDetails != nil &&
+		v.Status.UploadStatus == "processed" &&
+		v.Status.PrivacyStatus != "private" &&
+		v.ContentDetails.Duration != "" &&
+		v.ContentDetails.Duration != "P0D"
+}
+
+// IsPrivateVideo reports whether the video has been privated.
+func IsPrivateVideo(v *Video) bool {
+	return v.Status != nil && v.Status.PrivacyStatus == "private"
+}
+
+// BestThumbnail returns the url of the highest resolution thumbnail of video.
+func BestThumbnail(v *Video) string {
+	if v.Snippet == nil || v.Snippet.Thumbnails == nil {
+		return ""
+	}
+
+	t := v.Snippet.Thumbnails
+	for _, th := range []*Thumbnail{t.Maxres, t.Standard, t.High, t.Medium, t.Default} {
+		if th != nil && th.Url != "" {
+			return th.Url
+		}
+	}
+
+	return ""
+}
+
+var isoDurationRegexp = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseDuration parses ISO 8601 duration of contentDetails, e.g. PT1H2M3S.
+func ParseDuration(s string) (time.Duration, error) {
+	m := isoDurationRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, errors.New("invalid ISO 8601 duration: " + s)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var dur time.Duration
+	for i, unit := range units {
+		if m[i+1] != "" {
+			n, _ := strconv.Atoi(m[i+1])
+			dur += time.Duration(n) * unit
+		}
+	}
+
+	return dur, nil
+}
+
+// VideoDuration returns duration of video, or 0 if unknown.
+func VideoDuration(v *Video) time.Duration {
+	if v.ContentDetails == nil {
+		return 0
+	}
+
+	dur, _ := ParseDuration(v.ContentDetails.Duration)
+	return dur
+}
+
+// membersOnlyKeywords are explicit markers of members-only streams in titles,
+// words which merely mention membership are excluded.
+var membersOnlyKeywords = []string{
+	"members only", "members-only", "member only", "member-only",
+	"メンバー限定", "メン限", "メンバーシップ限定",
+	"會員限定", "会员限定",
+}
+
+// IsMembersOnly reports whether the video is a members-only stream.
+// YouTube Data API has no field of membership, members-only streams are
+// public videos marked in titles. snippet & status parts are required.
+func IsMembersOnly(v *Video) bool {
+	if v.Snippet == nil || v.Status == nil || v.Status.PrivacyStatus != "public" {
+		return false
+	}
+
+	title := strings.ToLower(v.Snippet.Title)
+	for _, k := range membersOnlyKeywords {
+		if strings.Contains(title, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAgeRestricted reports whether the video is age-restricted.
+// contentDetails part is required.
+func IsAgeRestricted(v *Video) bool {
+	return v.ContentDetails != nil && v.ContentDetails.ContentRating != nil &&
+		v.ContentDetails.ContentRating.YtRating == "ytAgeRestricted"
+}
+
+// IsRegionRestricted reports whether the video is blocked in some regions,
+// or only allowed in some regions. contentDetails part is required.
+func IsRegionRestricted(v *Video) bool {
+	if v.ContentDetails == nil || v.ContentDetails.RegionRestriction == nil {
+		return false
+	}
+
+	r := v.ContentDetails.RegionRestriction
+	return len(r.Blocked) != 0 || len(r.Allowed) != 0
+}